@@ -6,36 +6,89 @@ import (
 	"go/parser"
 	"go/token"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
-	"sync"
+	"time"
 )
 
 // Annotation represents parsed swagger annotations
 type Annotation struct {
-	Summary     string
+	OperationID     string
+	Router          *RouterAnnotation
+	Summary         string
+	Description     string
+	DescriptionFile string // from @Description file(path), relative to the handler's source file
+	DocComment      string // Go doc comment prose, i.e. the lines that are not annotations
+	Tags            []string
+	Accept          []string
+	Produce         []string
+	Security        []SecurityRequirement
+	Parameters      []ParamAnnotation
+	Successes       []SuccessResponse
+	Failures        []ErrorResponse
+	Headers         []HeaderAnnotation
+	Deprecation     *DeprecationAnnotation
+	Hidden          bool     // from @Hidden or @Ignore: the operation is left out of the spec
+	Audiences       []string // from @Audience: only specs for these audiences include the operation
+}
+
+// DeprecationAnnotation marks an operation as deprecated by an @Deprecated line.
+// Sunset is an optional removal date (YYYY-MM-DD) and Replacement optional guidance
+// such as "use /v2/users".
+type DeprecationAnnotation struct {
+	Sunset      string
+	Replacement string
+}
+
+// RouterAnnotation overrides the published path and, when given, the method of an operation.
+type RouterAnnotation struct {
+	Path   string
+	Method string
+}
+
+// HeaderAnnotation describes a response header declared by an @Header line for one status code.
+type HeaderAnnotation struct {
+	StatusCode  int
+	Type        string
+	Name        string
 	Description string
-	Tags        []string
-	Accept      []string
-	Produce     []string
-	Security    []string
-	Parameters  []ParamAnnotation
-	Success     *SuccessResponse
-	Failures    []ErrorResponse
 }
 
+// SuccessResponse describes one @Success line. StatusCode is DefaultStatusCode for the
+// "default" response, and an empty DataType documents a response without a body.
 type SuccessResponse struct {
 	StatusCode  int
 	DataType    string
 	Description string
 }
 
+// DefaultStatusCode is the status code recorded for the "default" response keyword.
+const DefaultStatusCode = 0
+
+// ParamAnnotation describes one @Param line, including swaggo-style attributes such as
+// default(1) or enums(a,b). Attribute values are kept as written and typed when the
+// parameter schema is built. A struct parameter ("@Param query ListUsersQuery") has no Name
+// and is expanded into one parameter per exported field.
 type ParamAnnotation struct {
-	Name        string
-	In          string
-	Type        string
-	Required    bool
-	Description string
+	Name             string
+	In               string
+	Type             string
+	Required         bool
+	Description      string
+	Default          string
+	Enums            []string
+	Minimum          *float64
+	Maximum          *float64
+	MinLength        *int
+	MaxLength        *int
+	Format           string
+	Example          string
+	CollectionFormat string
+	Deprecated       bool
+	ContentType      string   // formData parts only
+	PartHeaders      []string // formData parts only
 }
 
 type ErrorResponse struct {
@@ -44,9 +97,6 @@ type ErrorResponse struct {
 	Description string
 }
 
-var astCache = make(map[string]*ast.File)
-var astCacheMutex sync.RWMutex
-
 // AnnotationParsingError represents errors encountered while parsing annotation lines.
 // It contains one or more error messages for malformed annotation directives.
 type AnnotationParsingError struct {
@@ -58,92 +108,205 @@ func (e *AnnotationParsingError) Error() string {
 }
 
 // ParseAnnotations extracts OpenAPI annotations from Go source comments for a given function.
+// Methods are addressed by receiver type and name (e.g. "UserHandler.List"); a bare name only
+// matches top-level functions, so same-named methods on different handler types never collide.
 // It returns an Annotation struct and an error if any annotation lines were malformed.
 func ParseAnnotations(filePath, functionName string) (*Annotation, error) {
-	slog.Debug("[openapi] ParseAnnotations: called", "filePath", filePath, "functionName", functionName)
-	if filePath == "" || filePath == "<autogenerated>" ||
-		strings.Contains(filePath, "/go/pkg/mod/") ||
-		!strings.HasSuffix(filePath, ".go") {
-		slog.Debug("[openapi] ParseAnnotations: skipping file", "filePath", filePath)
+	logger := slog.Default()
+	logger.Debug("[openapi] ParseAnnotations: called", "filePath", filePath, "functionName", functionName)
+	astFile, err := parseAnnotationFile(token.NewFileSet(), filePath, logger)
+	if astFile == nil {
+		return nil, err
+	}
+
+	funcDecl := findFuncDecl(astFile, functionName, logger)
+	if funcDecl == nil {
+		logger.Debug("[openapi] ParseAnnotations: no comment found", "functionName", functionName)
+		return nil, nil
+	}
+	return annotationsFromDecl(funcDecl, filePath, logger), nil
+}
+
+// isAnnotationSource reports whether a handler source file can carry annotations,
+// excluding generated and module-cache files.
+func isAnnotationSource(filePath string) bool {
+	return filePath != "" && filePath != "<autogenerated>" &&
+		!strings.Contains(filePath, "/go/pkg/mod/") &&
+		strings.HasSuffix(filePath, ".go")
+}
+
+// parseAnnotationFile parses a handler source file with comments, recording its positions in fset.
+// It returns nil for generated or module-cache files.
+func parseAnnotationFile(fset *token.FileSet, filePath string, logger *slog.Logger) (*ast.File, error) {
+	if !isAnnotationSource(filePath) {
+		logger.Debug("[openapi] parseAnnotationFile: skipping file", "filePath", filePath)
 		return nil, nil
 	}
 
-	// First try to get the file from the global TypeIndex
-	var astFile *ast.File
-	if typeIndex != nil {
-		if file, exists := typeIndex.files[filePath]; exists {
-			slog.Debug("[openapi] ParseAnnotations: using TypeIndex cached file", "filePath", filePath)
-			astFile = file
+	logger.Debug("[openapi] parseAnnotationFile: parsing file", "filePath", filePath)
+	parsedFile, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
+	if err != nil {
+		logger.Debug("[openapi] parseAnnotationFile: failed to parse file", "err", err)
+		return nil, err
+	}
+	return parsedFile, nil
+}
+
+// loadAnnotationFile returns the parsed AST for a handler source file and the file set holding its
+// positions, preferring the generator's TypeIndex and falling back to its AST cache.
+// It returns nil for generated or module-cache files.
+func (g *Generator) loadAnnotationFile(filePath string) (*ast.File, *token.FileSet, error) {
+	if !isAnnotationSource(filePath) {
+		g.log().Debug("[openapi] loadAnnotationFile: skipping file", "filePath", filePath)
+		return nil, nil, nil
+	}
+
+	// First try to get the file from the TypeIndex
+	if idx := g.schemaGen.typeIndex; idx != nil {
+		if file, exists := idx.files[filePath]; exists {
+			g.log().Debug("[openapi] loadAnnotationFile: using TypeIndex cached file", "filePath", filePath)
+			return file, idx.fset, nil
 		}
 	}
 
 	// Fallback to our own cache if not in TypeIndex
-	if astFile == nil {
-		astCacheMutex.RLock()
-		file, exists := astCache[filePath]
-		astCacheMutex.RUnlock()
-
-		if exists {
-			slog.Debug("[openapi] ParseAnnotations: astCache hit", "filePath", filePath)
-			astFile = file
-		} else {
-			slog.Debug("[openapi] ParseAnnotations: parsing file (cache miss)", "filePath", filePath)
-			fset := token.NewFileSet()
-			parsedFile, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-			if err != nil {
-				slog.Debug("[openapi] ParseAnnotations: failed to parse file", "err", err)
-				return nil, err
-			}
+	g.astMutex.Lock()
+	defer g.astMutex.Unlock()
+	if file, exists := g.astCache[filePath]; exists {
+		g.log().Debug("[openapi] loadAnnotationFile: astCache hit", "filePath", filePath)
+		return file, g.astFset, nil
+	}
 
-			astCacheMutex.Lock()
-			astCache[filePath] = parsedFile
-			astFile = parsedFile
-			astCacheMutex.Unlock()
-		}
+	parsedFile, err := parseAnnotationFile(g.astFset, filePath, g.log())
+	if parsedFile != nil {
+		g.astCache[filePath] = parsedFile
 	}
+	return parsedFile, g.astFset, err
+}
 
-	// Find the function and its comment
-	var comment string
+// findFuncDecl returns the documented declaration matching a lookup name ("Name" or "Receiver.Name").
+func findFuncDecl(astFile *ast.File, functionName string, logger *slog.Logger) *ast.FuncDecl {
 	for _, decl := range astFile.Decls {
 		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
-			if funcDecl.Name.Name == functionName && funcDecl.Doc != nil {
-				slog.Debug("[openapi] ParseAnnotations: found function with doc", "functionName", functionName)
-				comment = funcDecl.Doc.Text()
-				break
+			if funcDeclName(funcDecl) == functionName && funcDecl.Doc != nil {
+				logger.Debug("[openapi] findFuncDecl: found function with doc", "functionName", functionName)
+				return funcDecl
 			}
 		}
 	}
+	return nil
+}
 
-	if comment == "" {
-		slog.Debug("[openapi] ParseAnnotations: no comment found", "functionName", functionName)
-		return nil, nil
+// annotationsFromDecl parses the doc comment of a function declaration declared in filePath.
+// Malformed annotation lines are logged and skipped.
+func annotationsFromDecl(funcDecl *ast.FuncDecl, filePath string, logger *slog.Logger) *Annotation {
+	logger.Debug("[openapi] annotationsFromDecl: parsing annotation comment")
+	annotation, err := parseAnnotationComment(funcDecl.Doc.Text(), logger)
+	if err != nil {
+		logger.Warn("[openapi] annotationsFromDecl: parsing errors", "error", err)
+	}
+	if annotation.DescriptionFile != "" {
+		loadDescriptionFile(annotation, filePath, logger)
 	}
+	return annotation
+}
 
-	slog.Debug("[openapi] ParseAnnotations: parsing annotation comment")
-	annotation, err := parseAnnotationComment(comment)
+// loadDescriptionFile appends the Markdown referenced by @Description file(...) to the description.
+// The path is resolved relative to the directory of the handler's source file.
+func loadDescriptionFile(annotation *Annotation, filePath string, logger *slog.Logger) {
+	path := annotation.DescriptionFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(filePath), path)
+	}
+	content, err := os.ReadFile(path)
 	if err != nil {
-		slog.Warn("[openapi] ParseAnnotations: parsing errors", "error", err)
+		logger.Warn("[openapi] loadDescriptionFile: cannot read description file", "path", path, "error", err)
+		return
 	}
-	return annotation, nil
+
+	markdown := strings.TrimSpace(string(content))
+	if annotation.Description != "" && markdown != "" {
+		annotation.Description += "\n\n" + markdown
+	} else if markdown != "" {
+		annotation.Description = markdown
+	}
+}
+
+// dedent removes the indentation shared by all lines, keeping deeper indentation such as
+// nested Markdown lists and indented code blocks.
+func dedent(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if n := len(line) - len(strings.TrimLeft(line, " \t")); indent == -1 || n < indent {
+			indent = n
+		}
+	}
+	dedented := make([]string, len(lines))
+	for i, line := range lines {
+		dedented[i] = line[indent:]
+	}
+	return dedented
 }
 
 // parseAnnotationComment parses both legacy and OpenAPI 3.1 annotations and reports malformed lines.
-func parseAnnotationComment(comment string) (*Annotation, error) {
+func parseAnnotationComment(comment string, logger *slog.Logger) (*Annotation, error) {
 	var errs []string
+	var description, prose, continuation []string
+	inDescription := false
 	annotation := &Annotation{}
 	lines := strings.Split(comment, "\n")
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+
+		// Indented lines continue the preceding @Description; nested indentation is kept
+		if inDescription && line != "" && !strings.HasPrefix(line, "@") && raw != strings.TrimLeft(raw, " \t") {
+			continuation = append(continuation, strings.TrimRight(raw, " \t"))
+			continue
+		}
+		description = append(description, dedent(continuation)...)
+		continuation = nil
+		inDescription = false
 		if line == "" {
+			prose = append(prose, "")
+			continue
+		}
+		if !strings.HasPrefix(line, "@") {
+			prose = append(prose, line)
 			continue
 		}
 
 		switch {
+		case strings.HasPrefix(line, "@ID "):
+			annotation.OperationID = strings.TrimSpace(strings.TrimPrefix(line, "@ID "))
+		case strings.HasPrefix(line, "@Router "):
+			router, err := parseRouterAnnotation(line, logger)
+			if err != nil {
+				errs = append(errs, err.Error())
+			} else {
+				annotation.Router = router
+			}
+		case line == "@Deprecated" || strings.HasPrefix(line, "@Deprecated "):
+			deprecation, err := parseDeprecatedAnnotation(line, logger)
+			if err != nil {
+				errs = append(errs, err.Error())
+			} else {
+				annotation.Deprecation = deprecation
+			}
+		case isHiddenAnnotation(line):
+			annotation.Hidden = true
+		case strings.HasPrefix(line, "@Audience "):
+			annotation.Audiences = append(annotation.Audiences, splitAudiences(strings.TrimPrefix(line, "@Audience "), ",")...)
 		case strings.HasPrefix(line, "@Summary "):
 			annotation.Summary = strings.TrimPrefix(line, "@Summary ")
-		case strings.HasPrefix(line, "@Description "):
-			annotation.Description = strings.TrimPrefix(line, "@Description ")
+		case line == "@Description" || strings.HasPrefix(line, "@Description "):
+			value := strings.TrimSpace(strings.TrimPrefix(line, "@Description"))
+			if path, ok := parseDescriptionFile(value); ok {
+				annotation.DescriptionFile = path
+				continue
+			}
+			description = append(description, value)
+			inDescription = true
 		case strings.HasPrefix(line, "@Tags "):
 			tags := strings.TrimPrefix(line, "@Tags ")
 			annotation.Tags = strings.Split(tags, ",")
@@ -152,25 +315,23 @@ func parseAnnotationComment(comment string) (*Annotation, error) {
 			}
 
 		case strings.HasPrefix(line, "@Accept"):
-			accept := strings.TrimSpace(strings.TrimPrefix(line, "@Accept"))
-			if accept == "" {
-				accept = "application/json"
-			}
-			annotation.Accept = append(annotation.Accept, accept)
+			accept := parseMediaTypes(strings.TrimPrefix(line, "@Accept"))
+			annotation.Accept = append(annotation.Accept, accept...)
 
 		case strings.HasPrefix(line, "@Produce"):
-			produce := strings.TrimSpace(strings.TrimPrefix(line, "@Produce"))
-			if produce == "" {
-				produce = "application/json"
-			}
-			annotation.Produce = append(annotation.Produce, produce)
+			produce := parseMediaTypes(strings.TrimPrefix(line, "@Produce"))
+			annotation.Produce = append(annotation.Produce, produce...)
 
 		case strings.HasPrefix(line, "@Security"):
-			security := strings.TrimSpace(strings.TrimPrefix(line, "@Security"))
-			annotation.Security = append(annotation.Security, security)
+			security, err := parseSecurityAnnotation(line, logger)
+			if err != nil {
+				errs = append(errs, err.Error())
+			} else {
+				annotation.Security = append(annotation.Security, security...)
+			}
 
 		case strings.HasPrefix(line, "@Param "):
-			param, err := parseParamAnnotation(line)
+			param, err := parseParamAnnotation(line, logger)
 			if err != nil {
 				errs = append(errs, err.Error())
 			} else {
@@ -178,74 +339,223 @@ func parseAnnotationComment(comment string) (*Annotation, error) {
 			}
 
 		case strings.HasPrefix(line, "@Success "):
-			succ, err := parseSuccessAnnotation(line)
+			succ, err := parseSuccessAnnotation(line, logger)
 			if err != nil {
 				errs = append(errs, err.Error())
 			} else {
-				annotation.Success = succ
+				annotation.Successes = append(annotation.Successes, *succ)
 			}
 
 		case strings.HasPrefix(line, "@Failure "):
-			fail, err := parseFailureAnnotation(line)
+			fails, err := parseFailureAnnotation(line, logger)
+			if err != nil {
+				errs = append(errs, err.Error())
+			} else {
+				annotation.Failures = append(annotation.Failures, fails...)
+			}
+
+		case strings.HasPrefix(line, "@Header "):
+			headers, err := parseHeaderAnnotation(line, logger)
 			if err != nil {
 				errs = append(errs, err.Error())
 			} else {
-				annotation.Failures = append(annotation.Failures, *fail)
+				annotation.Headers = append(annotation.Headers, headers...)
 			}
 		}
 	}
 
+	description = append(description, dedent(continuation)...)
+	annotation.Description = strings.Trim(strings.Join(description, "\n"), "\n")
+	annotation.DocComment = strings.Trim(strings.Join(prose, "\n"), "\n")
+
 	if len(errs) > 0 {
 		return annotation, &AnnotationParsingError{Messages: errs}
 	}
 	return annotation, nil
 }
 
+// mediaTypeAliases maps swaggo-style @Accept/@Produce shorthands to MIME types.
+var mediaTypeAliases = map[string]string{
+	"json":                  "application/json",
+	"xml":                   "text/xml",
+	"plain":                 "text/plain",
+	"html":                  "text/html",
+	"mpfd":                  "multipart/form-data",
+	"x-www-form-urlencoded": "application/x-www-form-urlencoded",
+	"json-api":              "application/vnd.api+json",
+	"json-stream":           "application/x-json-stream",
+	"octet-stream":          "application/octet-stream",
+	"png":                   "image/png",
+	"jpeg":                  "image/jpeg",
+	"gif":                   "image/gif",
+	"event-stream":          "text/event-stream",
+}
+
+// parseMediaTypes parses a comma- or space-separated media type list, expanding aliases.
+// An empty list defaults to application/json.
+func parseMediaTypes(value string) []string {
+	var mediaTypes []string
+	for _, mediaType := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if full, ok := mediaTypeAliases[mediaType]; ok {
+			mediaType = full
+		}
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	if len(mediaTypes) == 0 {
+		return []string{"application/json"}
+	}
+	return mediaTypes
+}
+
+// parseSecurityAnnotation parses an @Security line into security requirements.
+// "||" separates alternatives, "&&" combines schemes that must all be satisfied, scopes are listed
+// in brackets, and "none" yields an empty requirement marking the operation as public.
+func parseSecurityAnnotation(line string, logger *slog.Logger) ([]SecurityRequirement, error) {
+	logger.Debug("[openapi] parseSecurityAnnotation: called", "line", line)
+	// @Security OAuth2[read:users, write:users]
+	// @Security ApiKeyAuth || BearerAuth
+	// @Security none
+	content := strings.TrimSpace(strings.TrimPrefix(line, "@Security"))
+	if content == "" {
+		return nil, fmt.Errorf("invalid @Security annotation: %s", line)
+	}
+
+	var requirements []SecurityRequirement
+	for _, alternative := range strings.Split(content, "||") {
+		requirement := SecurityRequirement{}
+		for _, scheme := range strings.Split(alternative, "&&") {
+			scheme = strings.TrimSpace(scheme)
+			if scheme == "none" {
+				continue
+			}
+			name, scopes, err := parseSecurityScheme(scheme)
+			if err != nil {
+				return nil, fmt.Errorf("invalid @Security annotation: %s", line)
+			}
+			requirement[name] = scopes
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
+
+// parseSecurityScheme splits "Name[scope1, scope2]" into the scheme name and its scopes.
+// Scopes are never nil so requirements marshal as "Name": [].
+func parseSecurityScheme(scheme string) (string, []string, error) {
+	open := strings.Index(scheme, "[")
+	if open == -1 {
+		if scheme == "" || strings.ContainsAny(scheme, "] ") {
+			return "", nil, fmt.Errorf("invalid security scheme %q", scheme)
+		}
+		return scheme, []string{}, nil
+	}
+	if !strings.HasSuffix(scheme, "]") || open == 0 {
+		return "", nil, fmt.Errorf("invalid security scheme %q", scheme)
+	}
+
+	scopes := []string{}
+	for _, scope := range strings.Split(scheme[open+1:len(scheme)-1], ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return strings.TrimSpace(scheme[:open]), scopes, nil
+}
+
 // parseSuccessAnnotation parses an @Success line into SuccessResponse or returns an error.
-func parseSuccessAnnotation(line string) (*SuccessResponse, error) {
-	slog.Debug("[openapi] parseSuccessAnnotation: called", "line", line)
-	// @Success 200 {data} Type "Description"
-	content := strings.TrimPrefix(line, "@Success ")
+func parseSuccessAnnotation(line string, logger *slog.Logger) (*SuccessResponse, error) {
+	logger.Debug("[openapi] parseSuccessAnnotation: called", "line", line)
+	// @Success 200 {object} Type "Description"
+	// @Success 204 "Description"
+	// @Success default {object} Type "Description"
+	content := strings.TrimSpace(strings.TrimPrefix(line, "@Success "))
 	parts := strings.Fields(content)
-	if len(parts) < 2 {
+	if len(parts) < 1 {
 		return nil, fmt.Errorf("invalid @Success annotation: %s", line)
 	}
 
-	statusCode, err := strconv.Atoi(parts[0])
+	statusCode, err := parseStatusCode(parts[0])
 	if err != nil {
 		return nil, err
 	}
 
-	response := &SuccessResponse{StatusCode: statusCode}
-	remaining := strings.Join(parts[1:], " ")
+	remaining := strings.TrimSpace(strings.TrimPrefix(content, parts[0]))
+	response := &SuccessResponse{
+		StatusCode:  statusCode,
+		DataType:    parseResponseType(remaining),
+		Description: extractQuoted(remaining),
+	}
 
-	// Extract type from {data} Type or {object} Type
-	if strings.Contains(remaining, "{data}") || strings.Contains(remaining, "{object}") {
-		remaining = strings.Replace(remaining, "{data}", "", 1)
-		remaining = strings.Replace(remaining, "{object}", "", 1)
-		remaining = strings.TrimSpace(remaining)
+	return response, nil
+}
 
-		parts := strings.Fields(remaining)
-		if len(parts) > 0 {
-			response.DataType = parts[0]
-		}
+// parseStatusCode parses a numeric status code or the "default" keyword.
+func parseStatusCode(code string) (int, error) {
+	if code == "default" {
+		return DefaultStatusCode, nil
 	}
+	return strconv.Atoi(code)
+}
 
-	// Extract description from quotes
-	if start := strings.Index(remaining, "\""); start != -1 {
-		if end := strings.LastIndex(remaining, "\""); end != -1 && end > start {
-			response.Description = remaining[start+1 : end]
+// parseResponseType extracts the Go type from "{object} Type ..." or "{array} Type ...".
+// {array} yields a slice type; a missing type (e.g. `"No content"`) yields "".
+func parseResponseType(remaining string) string {
+	if !strings.HasPrefix(remaining, "{") {
+		return ""
+	}
+	end := strings.Index(remaining, "}")
+	if end == -1 {
+		return ""
+	}
+	format := remaining[1:end]
+	dataType := scanTypeToken(strings.TrimSpace(remaining[end+1:]))
+	if dataType == "" || strings.HasPrefix(dataType, "\"") {
+		return ""
+	}
+	if format == "array" {
+		return "[]" + dataType
+	}
+	return dataType
+}
+
+// scanTypeToken returns the leading type token, keeping generic arguments and field overrides
+// together even when they contain spaces, e.g. "Envelope{data=User, meta=Meta}".
+func scanTypeToken(s string) string {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case ' ', '\t':
+			if depth == 0 {
+				return s[:i]
+			}
 		}
 	}
+	return s
+}
 
-	return response, nil
+// extractQuoted returns the text between the first and last double quote, or "".
+func extractQuoted(s string) string {
+	if start := strings.Index(s, "\""); start != -1 {
+		if end := strings.LastIndex(s, "\""); end != -1 && end > start {
+			return s[start+1 : end]
+		}
+	}
+	return ""
 }
 
-func parseParamAnnotation(line string) (*ParamAnnotation, error) {
-	slog.Debug("[openapi] parseParamAnnotation: called", "line", line)
-	// @Param name in type required "description"
+func parseParamAnnotation(line string, logger *slog.Logger) (*ParamAnnotation, error) {
+	logger.Debug("[openapi] parseParamAnnotation: called", "line", line)
+	// @Param name in type required "description" attribute(value) ...
+	// @Param query StructType
 	content := strings.TrimPrefix(line, "@Param ")
-	parts := strings.Fields(content)
+	parts, rest := cutFields(content, 4)
+	if len(parts) == 2 && isStructParamLocation(parts[0]) {
+		return &ParamAnnotation{In: parts[0], Type: parts[1]}, nil
+	}
 	if len(parts) < 4 {
 		return nil, fmt.Errorf("invalid @Param annotation: %s", line)
 	}
@@ -258,37 +568,299 @@ func parseParamAnnotation(line string) (*ParamAnnotation, error) {
 	}
 
 	// Extract description
-	if start := strings.Index(content, "\""); start != -1 {
-		if end := strings.LastIndex(content, "\""); end != -1 && end > start {
-			param.Description = content[start+1 : end]
+	if strings.HasPrefix(rest, "\"") {
+		if end := strings.Index(rest[1:], "\""); end != -1 {
+			param.Description = rest[1 : end+1]
+			rest = rest[end+2:]
 		}
 	}
 
+	if err := parseParamAttributes(rest, param, logger); err != nil {
+		return nil, fmt.Errorf("invalid @Param annotation: %s: %w", line, err)
+	}
+
 	return param, nil
 }
 
-func parseFailureAnnotation(line string) (*ErrorResponse, error) {
-	slog.Debug("[openapi] parseFailureAnnotation: called", "line", line)
+// isStructParamLocation reports whether a struct may be expanded into parameters at the location.
+func isStructParamLocation(in string) bool {
+	return in == "query" || in == "header" || in == "cookie"
+}
+
+// cutFields splits off the first n whitespace-separated fields and returns them with the trimmed remainder.
+func cutFields(s string, n int) ([]string, string) {
+	var fields []string
+	rest := strings.TrimSpace(s)
+	for len(fields) < n && rest != "" {
+		end := strings.IndexAny(rest, " \t")
+		if end == -1 {
+			end = len(rest)
+		}
+		fields = append(fields, rest[:end])
+		rest = strings.TrimSpace(rest[end:])
+	}
+	return fields, rest
+}
+
+// parseParamAttributes applies swaggo-style attributes such as default(1), enums(a,b),
+// minimum(0), maxlength(64), format(date), example(x), collectionFormat(multi) and deprecated.
+// Unknown attributes are ignored; malformed values of known attributes are reported.
+func parseParamAttributes(attrs string, param *ParamAnnotation, logger *slog.Logger) error {
+	for attrs = strings.TrimSpace(attrs); attrs != ""; attrs = strings.TrimSpace(attrs) {
+		open := strings.IndexAny(attrs, "( ")
+		if open == -1 || attrs[open] == ' ' {
+			// Bare flag attribute
+			name := attrs
+			if open != -1 {
+				name = attrs[:open]
+			}
+			if strings.EqualFold(name, "deprecated") {
+				param.Deprecated = true
+			} else {
+				logger.Debug("[openapi] parseParamAttributes: ignoring unknown attribute", "attribute", name)
+			}
+			attrs = attrs[len(name):]
+			continue
+		}
+
+		end := strings.Index(attrs[open:], ")")
+		if end == -1 {
+			return fmt.Errorf("unterminated attribute %q", attrs)
+		}
+		name, value := strings.ToLower(attrs[:open]), strings.TrimSpace(attrs[open+1:open+end])
+		attrs = attrs[open+end+1:]
+
+		var err error
+		switch name {
+		case "default":
+			param.Default = value
+		case "enums":
+			for _, enum := range strings.Split(value, ",") {
+				param.Enums = append(param.Enums, strings.TrimSpace(enum))
+			}
+		case "minimum":
+			param.Minimum, err = parseFloatAttribute(value)
+		case "maximum":
+			param.Maximum, err = parseFloatAttribute(value)
+		case "minlength":
+			param.MinLength, err = parseIntAttribute(value)
+		case "maxlength":
+			param.MaxLength, err = parseIntAttribute(value)
+		case "format":
+			param.Format = value
+		case "example":
+			param.Example = value
+		case "collectionformat":
+			param.CollectionFormat = value
+		case "contenttype":
+			param.ContentType = value
+		case "headers":
+			for _, header := range strings.Split(value, ",") {
+				param.PartHeaders = append(param.PartHeaders, strings.TrimSpace(header))
+			}
+		case "deprecated":
+			param.Deprecated = value == "" || value == "true"
+		default:
+			logger.Debug("[openapi] parseParamAttributes: ignoring unknown attribute", "attribute", name)
+		}
+		if err != nil {
+			return fmt.Errorf("attribute %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// parseFloatAttribute parses a numeric attribute value.
+func parseFloatAttribute(value string) (*float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// parseIntAttribute parses an integer attribute value.
+func parseIntAttribute(value string) (*int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// parseFailureAnnotation parses an @Failure line into one ErrorResponse per listed status code.
+func parseFailureAnnotation(line string, logger *slog.Logger) ([]ErrorResponse, error) {
+	logger.Debug("[openapi] parseFailureAnnotation: called", "line", line)
 	// @Failure 400 {object} Type "Description"
-	content := strings.TrimPrefix(line, "@Failure ")
+	// @Failure 400,404,409 {object} Type "Description"
+	content := strings.TrimSpace(strings.TrimPrefix(line, "@Failure "))
 	parts := strings.Fields(content)
 	if len(parts) < 2 {
 		return nil, fmt.Errorf("invalid @Failure annotation: %s", line)
 	}
 
-	statusCode, err := strconv.Atoi(parts[0])
+	statusCodes, err := parseStatusCodes(parts[0])
 	if err != nil {
 		return nil, err
 	}
 
-	failure := &ErrorResponse{StatusCode: statusCode}
+	remaining := strings.TrimSpace(strings.TrimPrefix(content, parts[0]))
+	dataType := parseResponseType(remaining)
+	description := extractQuoted(remaining)
+
+	failures := make([]ErrorResponse, 0, len(statusCodes))
+	for _, statusCode := range statusCodes {
+		failures = append(failures, ErrorResponse{
+			StatusCode:  statusCode,
+			Type:        dataType,
+			Description: description,
+		})
+	}
+	return failures, nil
+}
 
-	// Extract description
-	if start := strings.Index(content, "\""); start != -1 {
-		if end := strings.LastIndex(content, "\""); end != -1 && end > start {
-			failure.Description = content[start+1 : end]
+// applyDocCommentFallback fills a missing Summary with the first sentence of the Go doc comment,
+// without the leading identifier ("GetUsers retrieves users." -> "Retrieves users"), and a missing
+// Description with the remaining prose.
+func applyDocCommentFallback(annotation *Annotation, identifier string) {
+	doc := annotation.DocComment
+	if doc == "" {
+		return
+	}
+
+	// The first sentence ends at a period followed by whitespace, or at the first blank line
+	end, rest := len(doc), len(doc)
+	for i := 0; i < len(doc); i++ {
+		if doc[i] == '.' && (i+1 == len(doc) || doc[i+1] == ' ' || doc[i+1] == '\n') {
+			end, rest = i, i+1
+			break
+		}
+		if doc[i] == '\n' && i+1 < len(doc) && doc[i+1] == '\n' {
+			end, rest = i, i
+			break
+		}
+	}
+
+	if annotation.Summary == "" {
+		summary := strings.Join(strings.Fields(doc[:end]), " ")
+		if identifier != "" {
+			if trimmed, ok := strings.CutPrefix(summary, identifier+" "); ok {
+				summary = capitalize(trimmed)
+			}
+		}
+		annotation.Summary = summary
+	}
+	if annotation.Description == "" {
+		annotation.Description = strings.TrimSpace(doc[rest:])
+	}
+}
+
+// parseDescriptionFile extracts the path from a "file(docs/users/list.md)" description value.
+func parseDescriptionFile(value string) (string, bool) {
+	if !strings.HasPrefix(value, "file(") || !strings.HasSuffix(value, ")") {
+		return "", false
+	}
+	path := strings.TrimSpace(value[len("file(") : len(value)-1])
+	return path, path != ""
+}
+
+// parseDeprecatedAnnotation parses an @Deprecated line with an optional sunset date and
+// quoted replacement, e.g. `@Deprecated 2026-12-31 "use /v2/users"`.
+func parseDeprecatedAnnotation(line string, logger *slog.Logger) (*DeprecationAnnotation, error) {
+	logger.Debug("[openapi] parseDeprecatedAnnotation: called", "line", line)
+	content := strings.TrimSpace(strings.TrimPrefix(line, "@Deprecated"))
+	deprecation := &DeprecationAnnotation{Replacement: extractQuoted(content)}
+
+	if quote := strings.Index(content, "\""); quote != -1 {
+		content = strings.TrimSpace(content[:quote])
+	}
+	if content != "" {
+		if _, err := time.Parse(time.DateOnly, content); err != nil {
+			return nil, fmt.Errorf("invalid @Deprecated sunset date %q: %s", content, line)
+		}
+		deprecation.Sunset = content
+	}
+	return deprecation, nil
+}
+
+// isHiddenAnnotation reports whether a line is @Hidden or @Ignore, optionally followed by a reason.
+func isHiddenAnnotation(line string) bool {
+	for _, keyword := range []string{"@Hidden", "@Ignore"} {
+		if line == keyword || strings.HasPrefix(line, keyword+" ") {
+			return true
 		}
 	}
+	return false
+}
+
+// parseRouterAnnotation parses an @Router line, e.g. "@Router /users/{id} [get]".
+func parseRouterAnnotation(line string, logger *slog.Logger) (*RouterAnnotation, error) {
+	logger.Debug("[openapi] parseRouterAnnotation: called", "line", line)
+	parts := strings.Fields(strings.TrimPrefix(line, "@Router "))
+	if len(parts) == 0 || len(parts) > 2 || !strings.HasPrefix(parts[0], "/") {
+		return nil, fmt.Errorf("invalid @Router annotation: %s", line)
+	}
+
+	router := &RouterAnnotation{Path: parts[0]}
+	if len(parts) == 2 {
+		method := strings.ToUpper(strings.Trim(parts[1], "[]"))
+		if !strings.HasPrefix(parts[1], "[") || !strings.HasSuffix(parts[1], "]") || !isHTTPMethod(method) {
+			return nil, fmt.Errorf("invalid @Router method: %s", line)
+		}
+		router.Method = method
+	}
+	return router, nil
+}
+
+// isHTTPMethod reports whether method is an HTTP method that OpenAPI path items can describe.
+func isHTTPMethod(method string) bool {
+	switch method {
+	case "GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE":
+		return true
+	}
+	return false
+}
 
-	return failure, nil
+// parseHeaderAnnotation parses an @Header line into one HeaderAnnotation per listed status code.
+func parseHeaderAnnotation(line string, logger *slog.Logger) ([]HeaderAnnotation, error) {
+	logger.Debug("[openapi] parseHeaderAnnotation: called", "line", line)
+	// @Header 201 {string} Location "Description"
+	// @Header 200,206 {integer} X-Total-Count "Description"
+	content := strings.TrimSpace(strings.TrimPrefix(line, "@Header "))
+	parts := strings.Fields(content)
+	if len(parts) < 3 || !strings.HasPrefix(parts[1], "{") || !strings.HasSuffix(parts[1], "}") {
+		return nil, fmt.Errorf("invalid @Header annotation: %s", line)
+	}
+
+	statusCodes, err := parseStatusCodes(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	headerType := strings.Trim(parts[1], "{}")
+	description := extractQuoted(strings.TrimSpace(strings.TrimPrefix(content, parts[0])))
+
+	headers := make([]HeaderAnnotation, 0, len(statusCodes))
+	for _, statusCode := range statusCodes {
+		headers = append(headers, HeaderAnnotation{
+			StatusCode:  statusCode,
+			Type:        headerType,
+			Name:        parts[2],
+			Description: description,
+		})
+	}
+	return headers, nil
+}
+
+// parseStatusCodes parses a comma-separated list of status codes, e.g. "400,404,409".
+func parseStatusCodes(codes string) ([]int, error) {
+	var statusCodes []int
+	for _, code := range strings.Split(codes, ",") {
+		statusCode, err := parseStatusCode(strings.TrimSpace(code))
+		if err != nil {
+			return nil, err
+		}
+		statusCodes = append(statusCodes, statusCode)
+	}
+	return statusCodes, nil
 }
@@ -1,6 +1,9 @@
 package openapi
 
 import (
+	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -45,11 +48,11 @@ func TestParseAnnotations_AllAnnotations(t *testing.T) {
 	if len(annotation.Produce) != 1 || annotation.Produce[0] != "application/json" {
 		t.Errorf("expected Produce [application/json], got %v", annotation.Produce)
 	}
-	if len(annotation.Security) != 1 || annotation.Security[0] != "ApiKeyAuth" {
+	if len(annotation.Security) != 1 || annotation.Security[0]["ApiKeyAuth"] == nil {
 		t.Errorf("expected Security [ApiKeyAuth], got %v", annotation.Security)
 	}
-	if annotation.Success == nil || annotation.Success.DataType != "TestResponse" {
-		t.Errorf("expected success DataType 'TestResponse', got %+v", annotation.Success)
+	if len(annotation.Successes) != 1 || annotation.Successes[0].DataType != "TestResponse" {
+		t.Errorf("expected success DataType 'TestResponse', got %+v", annotation.Successes)
 	}
 	if len(annotation.Failures) != 1 || annotation.Failures[0].StatusCode != 400 {
 		t.Errorf("expected failure 400, got %+v", annotation.Failures)
@@ -68,7 +71,7 @@ func TestParseAnnotations_Empty(t *testing.T) {
 
 func Test_parseParamAnnotation(t *testing.T) {
 	line := "@Param foo query int true \"desc\""
-	param, err := parseParamAnnotation(line)
+	param, err := parseParamAnnotation(line, slog.Default())
 	if err != nil {
 		t.Fatalf("parseParamAnnotation error: %v", err)
 	}
@@ -80,7 +83,7 @@ func Test_parseParamAnnotation(t *testing.T) {
 
 func Test_parseSuccessAnnotation(t *testing.T) {
 	line := "@Success 201 {object} Foo \"desc\""
-	succ, err := parseSuccessAnnotation(line)
+	succ, err := parseSuccessAnnotation(line, slog.Default())
 	if err != nil {
 		t.Fatalf("parseSuccessAnnotation error: %v", err)
 	}
@@ -89,13 +92,284 @@ func Test_parseSuccessAnnotation(t *testing.T) {
 	}
 }
 
+func Test_parseSuccessAnnotation_Variants(t *testing.T) {
+	tests := []struct {
+		line string
+		want SuccessResponse
+	}{
+		{`@Success 204`, SuccessResponse{StatusCode: 204}},
+		{`@Success 204 "Deleted"`, SuccessResponse{StatusCode: 204, Description: "Deleted"}},
+		{`@Success 200 {array} User "Users"`, SuccessResponse{StatusCode: 200, DataType: "[]User", Description: "Users"}},
+		{`@Success default {object} Foo "Fallback"`, SuccessResponse{StatusCode: DefaultStatusCode, DataType: "Foo", Description: "Fallback"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.line, func(t *testing.T) {
+			succ, err := parseSuccessAnnotation(tc.line, slog.Default())
+			AssertNoError(t, err)
+			AssertEqual(t, tc.want, *succ)
+		})
+	}
+}
+
+func Test_parseSuccessAnnotation_CompositeTypes(t *testing.T) {
+	tests := map[string]string{
+		`@Success 200 {object} Envelope[User] "OK"`:                    "Envelope[User]",
+		`@Success 200 {object} Envelope[Page[models.User]] "OK"`:       "Envelope[Page[models.User]]",
+		`@Success 200 {array} Envelope[User] "OK"`:                     "[]Envelope[User]",
+		`@Success 200 {object} Envelope{data=User} "OK"`:               "Envelope{data=User}",
+		`@Success 200 {object} Envelope{data=[]User, meta=Meta} "OK"`:  "Envelope{data=[]User, meta=Meta}",
+		`@Success 200 {object} pkg.Pair[string, int] "Pair of values"`: "pkg.Pair[string, int]",
+	}
+	for line, want := range tests {
+		succ, err := parseSuccessAnnotation(line, slog.Default())
+		AssertNoError(t, err)
+		AssertEqual(t, want, succ.DataType)
+	}
+}
+
+func TestParseAnnotationComment_MultipleSuccess(t *testing.T) {
+	annotation, err := parseAnnotationComment("@Success 200 {object} Foo \"OK\"\n@Success 202 {object} Job \"Accepted\"", slog.Default())
+	AssertNoError(t, err)
+	if len(annotation.Successes) != 2 {
+		t.Fatalf("expected 2 success responses, got %+v", annotation.Successes)
+	}
+	AssertEqual(t, 202, annotation.Successes[1].StatusCode)
+}
+
 func Test_parseFailureAnnotation(t *testing.T) {
 	line := "@Failure 404 {object} Bar \"not found\""
-	fail, err := parseFailureAnnotation(line)
+	fails, err := parseFailureAnnotation(line, slog.Default())
 	if err != nil {
 		t.Fatalf("parseFailureAnnotation error: %v", err)
 	}
-	if fail == nil || fail.StatusCode != 404 || fail.Description != "not found" {
-		t.Errorf("unexpected failure: %+v", fail)
+	if len(fails) != 1 || fails[0].StatusCode != 404 || fails[0].Type != "Bar" || fails[0].Description != "not found" {
+		t.Errorf("unexpected failure: %+v", fails)
+	}
+}
+
+func Test_parseFailureAnnotation_MultipleCodes(t *testing.T) {
+	fails, err := parseFailureAnnotation(`@Failure 400,404,409 {object} ApiError "Client error"`, slog.Default())
+	AssertNoError(t, err)
+	AssertDeepEqual(t, []ErrorResponse{
+		{StatusCode: 400, Type: "ApiError", Description: "Client error"},
+		{StatusCode: 404, Type: "ApiError", Description: "Client error"},
+		{StatusCode: 409, Type: "ApiError", Description: "Client error"},
+	}, fails)
+
+	if _, err := parseFailureAnnotation(`@Failure 400,abc {object} ApiError "bad"`, slog.Default()); err == nil {
+		t.Error("expected error for invalid status code list")
+	}
+}
+
+func Test_parseHeaderAnnotation(t *testing.T) {
+	headers, err := parseHeaderAnnotation(`@Header 200,206 {integer} X-Total-Count "Total number of items"`, slog.Default())
+	AssertNoError(t, err)
+	AssertDeepEqual(t, []HeaderAnnotation{
+		{StatusCode: 200, Type: "integer", Name: "X-Total-Count", Description: "Total number of items"},
+		{StatusCode: 206, Type: "integer", Name: "X-Total-Count", Description: "Total number of items"},
+	}, headers)
+
+	headers, err = parseHeaderAnnotation(`@Header 201 {string} Location`, slog.Default())
+	AssertNoError(t, err)
+	AssertEqual(t, "Location", headers[0].Name)
+	AssertEqual(t, "", headers[0].Description)
+
+	for _, line := range []string{`@Header 201 Location "URL"`, `@Header abc {string} Location`, `@Header 201 {string}`} {
+		if _, err := parseHeaderAnnotation(line, slog.Default()); err == nil {
+			t.Errorf("expected error for %q", line)
+		}
+	}
+}
+
+func Test_parseDeprecatedAnnotation(t *testing.T) {
+	tests := map[string]DeprecationAnnotation{
+		"@Deprecated":                            {},
+		"@Deprecated 2026-12-31":                 {Sunset: "2026-12-31"},
+		`@Deprecated "use /v2/users"`:            {Replacement: "use /v2/users"},
+		`@Deprecated 2026-12-31 "use /v2/users"`: {Sunset: "2026-12-31", Replacement: "use /v2/users"},
+	}
+	for line, want := range tests {
+		deprecation, err := parseDeprecatedAnnotation(line, slog.Default())
+		AssertNoError(t, err)
+		AssertDeepEqual(t, &want, deprecation)
+	}
+
+	if _, err := parseDeprecatedAnnotation("@Deprecated 31/12/2026", slog.Default()); err == nil {
+		t.Error("expected error for malformed sunset date")
+	}
+}
+
+func TestParseAnnotationComment_MultiLineDescription(t *testing.T) {
+	comment := "ListUsers returns users.\n" +
+		"@Summary List users\n" +
+		"@Description Returns a paginated list of users.\n" +
+		"@Description\n" +
+		"@Description Supported filters:\n" +
+		"    - status\n" +
+		"    - role\n" +
+		"Trailing prose is not part of the description.\n" +
+		"@Tags users\n"
+	annotation, err := parseAnnotationComment(comment, slog.Default())
+	AssertNoError(t, err)
+	AssertEqual(t, "Returns a paginated list of users.\n\nSupported filters:\n- status\n- role", annotation.Description)
+	AssertDeepEqual(t, []string{"users"}, annotation.Tags)
+
+	comment = "@Description Filters:\n" +
+		"  - status\n" +
+		"    - active\n" +
+		"    - banned\n" +
+		"  - role\n" +
+		"@Description Example:\n" +
+		"      curl /users\n"
+	annotation, err = parseAnnotationComment(comment, slog.Default())
+	AssertNoError(t, err)
+	AssertEqual(t, "Filters:\n- status\n  - active\n  - banned\n- role\nExample:\ncurl /users", annotation.Description)
+}
+
+func TestApplyDocCommentFallback(t *testing.T) {
+	comment := "GetUsers retrieves a paginated\nlist of users. Results are sorted by name.\n\nDeleted users are excluded.\n@Tags users\n"
+	annotation, err := parseAnnotationComment(comment, slog.Default())
+	AssertNoError(t, err)
+	applyDocCommentFallback(annotation, "GetUsers")
+	AssertEqual(t, "Retrieves a paginated list of users", annotation.Summary)
+	AssertEqual(t, "Results are sorted by name.\n\nDeleted users are excluded.", annotation.Description)
+
+	// Annotations take precedence and unrelated leading words are kept
+	annotation, err = parseAnnotationComment("Health reports liveness\n\nAlways returns 200.\n@Summary Liveness probe\n", slog.Default())
+	AssertNoError(t, err)
+	applyDocCommentFallback(annotation, "Ping")
+	AssertEqual(t, "Liveness probe", annotation.Summary)
+	AssertEqual(t, "Always returns 200.", annotation.Description)
+
+	annotation = &Annotation{DocComment: "Health reports liveness"}
+	applyDocCommentFallback(annotation, "Ping")
+	AssertEqual(t, "Health reports liveness", annotation.Summary)
+	AssertEqual(t, "", annotation.Description)
+}
+
+func TestLoadDescriptionFile(t *testing.T) {
+	dir := t.TempDir()
+	AssertNoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0o755))
+	AssertNoError(t, os.WriteFile(filepath.Join(dir, "docs", "list.md"), []byte("# Listing\n\nDetails.\n"), 0o644))
+
+	annotation, err := parseAnnotationComment("@Description Short intro.\n@Description file(docs/list.md)\n", slog.Default())
+	AssertNoError(t, err)
+	AssertEqual(t, "docs/list.md", annotation.DescriptionFile)
+
+	loadDescriptionFile(annotation, filepath.Join(dir, "handlers.go"), slog.Default())
+	AssertEqual(t, "Short intro.\n\n# Listing\n\nDetails.", annotation.Description)
+
+	missing := &Annotation{Description: "Kept", DescriptionFile: "missing.md"}
+	loadDescriptionFile(missing, filepath.Join(dir, "handlers.go"), slog.Default())
+	AssertEqual(t, "Kept", missing.Description)
+}
+
+func Test_parseRouterAnnotation(t *testing.T) {
+	router, err := parseRouterAnnotation("@Router /users/{id} [get]", slog.Default())
+	AssertNoError(t, err)
+	AssertDeepEqual(t, &RouterAnnotation{Path: "/users/{id}", Method: "GET"}, router)
+
+	router, err = parseRouterAnnotation("@Router /users", slog.Default())
+	AssertNoError(t, err)
+	AssertDeepEqual(t, &RouterAnnotation{Path: "/users"}, router)
+
+	for _, line := range []string{"@Router users [get]", "@Router /users [fetch]", "@Router /users get", "@Router /users [get] extra"} {
+		if _, err := parseRouterAnnotation(line, slog.Default()); err == nil {
+			t.Errorf("expected error for %q", line)
+		}
+	}
+}
+
+func TestParseAnnotationComment_IDAndRouter(t *testing.T) {
+	annotation, err := parseAnnotationComment("@ID listUsers\n@Router /users [post]\n", slog.Default())
+	AssertNoError(t, err)
+	AssertEqual(t, "listUsers", annotation.OperationID)
+	AssertEqual(t, "POST", annotation.Router.Method)
+}
+
+func TestParseAnnotationComment_Hidden(t *testing.T) {
+	for _, comment := range []string{"@Hidden\n", "@Ignore\n", "@Summary Debug\n@Hidden internal only\n"} {
+		annotation, err := parseAnnotationComment(comment, slog.Default())
+		AssertNoError(t, err)
+		AssertEqual(t, true, annotation.Hidden)
+	}
+
+	annotation, err := parseAnnotationComment("@Summary Visible\n@HiddenField x\n", slog.Default())
+	AssertNoError(t, err)
+	AssertEqual(t, false, annotation.Hidden)
+}
+
+func Test_parseMediaTypes(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", []string{"application/json"}},
+		{"json", []string{"application/json"}},
+		{"json,xml", []string{"application/json", "text/xml"}},
+		{"mpfd x-www-form-urlencoded", []string{"multipart/form-data", "application/x-www-form-urlencoded"}},
+		{"octet-stream", []string{"application/octet-stream"}},
+		{"application/vnd.acme+json", []string{"application/vnd.acme+json"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			AssertDeepEqual(t, tc.want, parseMediaTypes(tc.value))
+		})
+	}
+}
+
+func Test_parseSecurityAnnotation(t *testing.T) {
+	tests := []struct {
+		line string
+		want []SecurityRequirement
+	}{
+		{"@Security BearerAuth", []SecurityRequirement{{"BearerAuth": {}}}},
+		{"@Security OAuth2[read:users, write:users]", []SecurityRequirement{{"OAuth2": {"read:users", "write:users"}}}},
+		{"@Security ApiKeyAuth || BearerAuth", []SecurityRequirement{{"ApiKeyAuth": {}}, {"BearerAuth": {}}}},
+		{"@Security ApiKeyAuth && OAuth2[admin]", []SecurityRequirement{{"ApiKeyAuth": {}, "OAuth2": {"admin"}}}},
+		{"@Security none", []SecurityRequirement{{}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.line, func(t *testing.T) {
+			got, err := parseSecurityAnnotation(tc.line, slog.Default())
+			AssertNoError(t, err)
+			AssertDeepEqual(t, tc.want, got)
+		})
+	}
+
+	for _, line := range []string{"@Security", "@Security OAuth2[read", "@Security [read]"} {
+		if _, err := parseSecurityAnnotation(line, slog.Default()); err == nil {
+			t.Errorf("expected error for %q", line)
+		}
+	}
+}
+
+func Test_parseParamAnnotation_Attributes(t *testing.T) {
+	line := `@Param status query string false "Order status" default(open) enums(open, closed) minlength(2) maxlength(10) format(slug) example(open) collectionFormat(multi) deprecated`
+	param, err := parseParamAnnotation(line, slog.Default())
+	AssertNoError(t, err)
+	AssertEqual(t, "Order status", param.Description)
+	AssertEqual(t, "open", param.Default)
+	AssertDeepEqual(t, []string{"open", "closed"}, param.Enums)
+	AssertEqual(t, 2, *param.MinLength)
+	AssertEqual(t, 10, *param.MaxLength)
+	AssertEqual(t, "slug", param.Format)
+	AssertEqual(t, "open", param.Example)
+	AssertEqual(t, "multi", param.CollectionFormat)
+	AssertEqual(t, true, param.Deprecated)
+
+	param, err = parseParamAnnotation(`@Param page query int false "Page (1-based)" minimum(1) maximum(100)`, slog.Default())
+	AssertNoError(t, err)
+	AssertEqual(t, "Page (1-based)", param.Description)
+	AssertEqual(t, 1.0, *param.Minimum)
+	AssertEqual(t, 100.0, *param.Maximum)
+
+	param, err = parseParamAnnotation(`@Param avatar formData file true "Avatar" contentType(image/png,image/jpeg) headers(X-Checksum, X-Source)`, slog.Default())
+	AssertNoError(t, err)
+	AssertEqual(t, "image/png,image/jpeg", param.ContentType)
+	AssertDeepEqual(t, []string{"X-Checksum", "X-Source"}, param.PartHeaders)
+
+	if _, err := parseParamAnnotation(`@Param page query int false "Page" minimum(one)`, slog.Default()); err == nil {
+		t.Error("expected error for non-numeric minimum")
 	}
 }
@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+)
+
+// schemaRefPrefix is the JSON reference prefix of component schemas.
+const schemaRefPrefix = "#/components/schemas/"
+
+// visibleTo reports whether something restricted to audiences is published in a spec for audience.
+// An empty audience selects the complete spec, and an empty restriction means public.
+func visibleTo(audiences []string, audience string) bool {
+	if audience == "" || len(audiences) == 0 {
+		return true
+	}
+	for _, a := range audiences {
+		if a == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAudiences splits an audience list on sep, trimming blanks.
+func splitAudiences(list, sep string) []string {
+	var audiences []string
+	for _, a := range strings.Split(list, sep) {
+		if a = strings.TrimSpace(a); a != "" {
+			audiences = append(audiences, a)
+		}
+	}
+	return audiences
+}
+
+// tagAudiences returns the audiences of a struct field from its openapi tag,
+// e.g. `openapi:"audience=internal|partner"`.
+func tagAudiences(tag string) []string {
+	for _, part := range strings.Split(extractTag(tag, "openapi"), ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && strings.TrimSpace(key) == "audience" {
+			return splitAudiences(value, "|")
+		}
+	}
+	return nil
+}
+
+// pruneUnreachableSchemas removes component schemas that are not referenced, directly or through
+// other schemas, from the paths, webhooks or the remaining components.
+func pruneUnreachableSchemas(spec *Spec, logger *slog.Logger) {
+	if spec.Components == nil || len(spec.Components.Schemas) == 0 {
+		return
+	}
+
+	roots := *spec.Components
+	roots.Schemas = nil
+	pending := schemaRefs(spec.Paths, logger)
+	pending = append(pending, schemaRefs(spec.Webhooks, logger)...)
+	pending = append(pending, schemaRefs(roots, logger)...)
+
+	reachable := make(map[string]bool)
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if reachable[name] {
+			continue
+		}
+		reachable[name] = true
+		if schema, ok := spec.Components.Schemas[name]; ok {
+			pending = append(pending, schemaRefs(schema, logger)...)
+		}
+	}
+
+	for name := range spec.Components.Schemas {
+		if !reachable[name] {
+			logger.Debug("[openapi] pruneUnreachableSchemas: removing schema", "name", name)
+			delete(spec.Components.Schemas, name)
+		}
+	}
+}
+
+// schemaRefs returns the component schema names referenced anywhere in v's JSON encoding.
+func schemaRefs(v interface{}, logger *slog.Logger) []string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		logger.Warn("[openapi] schemaRefs: failed to encode", "error", err)
+		return nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	var refs []string
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := n["$ref"].(string); ok && strings.HasPrefix(ref, schemaRefPrefix) {
+				refs = append(refs, strings.TrimPrefix(ref, schemaRefPrefix))
+			}
+			for _, child := range n {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range n {
+				walk(child)
+			}
+		}
+	}
+	walk(doc)
+	return refs
+}
@@ -0,0 +1,152 @@
+package openapi
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AudienceTestAccount is returned to every audience, with some fields restricted.
+type AudienceTestAccount struct {
+	ID         string                   `json:"id"`
+	Name       string                   `json:"name"`
+	RiskScore  float64                  `json:"risk_score" openapi:"audience=internal"`
+	PartnerRef string                   `json:"partner_ref" openapi:"format=uuid,audience=internal|partner"`
+	Audit      *AudienceTestAuditRecord `json:"audit,omitempty" openapi:"audience=internal"`
+}
+
+// AudienceTestAuditRecord is only reachable through internal fields and operations.
+type AudienceTestAuditRecord struct {
+	Actor string `json:"actor"`
+}
+
+// AudienceTestQuery filters accounts.
+type AudienceTestQuery struct {
+	Name  string `query:"name"`
+	Shard int    `query:"shard" openapi:"audience=internal"`
+}
+
+// getTestAudienceAccount returns an account.
+// @Summary Get account
+// @Tags accounts
+// @Param query AudienceTestQuery
+// @Success 200 {object} AudienceTestAccount
+func getTestAudienceAccount(w http.ResponseWriter, r *http.Request) {}
+
+// getTestAudienceAudit returns the audit trail.
+// @Summary Account audit
+// @Tags audit
+// @Audience internal
+// @Success 200 {object} AudienceTestAuditRecord
+func getTestAudienceAudit(w http.ResponseWriter, r *http.Request) {}
+
+// listTestPartnerAccounts lists accounts shared with partners.
+// @Summary Partner accounts
+// @Tags partners
+// @Audience internal, partner
+// @Success 200 {array} AudienceTestAccount
+func listTestPartnerAccounts(w http.ResponseWriter, r *http.Request) {}
+
+func audienceTestRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/accounts", getTestAudienceAccount)
+	r.Get("/accounts/audit", getTestAudienceAudit)
+	r.Get("/partner/accounts", listTestPartnerAccounts)
+	return r
+}
+
+func TestVisibleTo(t *testing.T) {
+	AssertEqual(t, true, visibleTo(nil, ""))
+	AssertEqual(t, true, visibleTo([]string{"internal"}, ""))
+	AssertEqual(t, true, visibleTo(nil, "partner"))
+	AssertEqual(t, true, visibleTo([]string{"internal", "partner"}, "partner"))
+	AssertEqual(t, false, visibleTo([]string{"internal"}, "partner"))
+}
+
+func TestTagAudiences(t *testing.T) {
+	AssertDeepEqual(t, []string{"internal", "partner"}, tagAudiences(`json:"x" openapi:"format=uuid,audience=internal|partner"`))
+	AssertEqual(t, 0, len(tagAudiences(`json:"x" openapi:"format=uuid"`)))
+}
+
+func TestParseAnnotationComment_Audience(t *testing.T) {
+	annotation, err := parseAnnotationComment("@Summary Partner accounts\n@Audience internal, partner\n", slog.Default())
+	AssertNoError(t, err)
+	AssertDeepEqual(t, []string{"internal", "partner"}, annotation.Audiences)
+}
+
+func TestGenerateSpec_Audiences(t *testing.T) {
+	idx := newTestIndex(t, "audience_test.go")
+	router := audienceTestRouter()
+	const account = "openapi.AudienceTestAccount"
+	const audit = "openapi.AudienceTestAuditRecord"
+
+	full := NewGeneratorWithCache(idx).GenerateSpec(router, Config{Title: "Test", Version: "1.0.0"})
+	AssertEqual(t, 3, len(full.Paths))
+	AssertEqual(t, 5, len(full.Components.Schemas[account].Properties))
+
+	partner := NewGeneratorWithCache(idx).GenerateSpec(router, Config{Title: "Test", Version: "1.0.0", Audience: "partner"})
+	if _, ok := partner.Paths["/accounts/audit"]; ok {
+		t.Error("expected internal operation to be omitted from the partner spec")
+	}
+	if item := partner.Paths["/partner/accounts"]; item == nil || item.Get == nil {
+		t.Error("expected partner operation in the partner spec")
+	}
+	schema := partner.Components.Schemas[account]
+	AssertEqual(t, 3, len(schema.Properties))
+	if _, ok := schema.Properties["risk_score"]; ok {
+		t.Error("expected internal field to be omitted from the partner spec")
+	}
+	AssertEqual(t, "uuid", schema.Properties["partner_ref"].Format)
+	for _, name := range schema.Required {
+		if name == "risk_score" {
+			t.Error("expected omitted field to be dropped from required")
+		}
+	}
+	if _, ok := partner.Components.Schemas[audit]; ok {
+		t.Error("expected unreachable internal schema to be pruned")
+	}
+	for _, tag := range partner.Tags {
+		if tag.Name == "audit" {
+			t.Error("expected tag used only by internal operations to be omitted")
+		}
+	}
+	for _, param := range partner.Paths["/accounts"].Get.Parameters {
+		if param.Name == "shard" {
+			t.Error("expected internal struct parameter to be omitted")
+		}
+	}
+
+	public := NewGeneratorWithCache(idx).GenerateSpec(router, Config{Title: "Test", Version: "1.0.0", Audience: "public"})
+	AssertEqual(t, 1, len(public.Paths))
+	AssertEqual(t, 2, len(public.Components.Schemas[account].Properties))
+}
+
+func TestPruneUnreachableSchemas(t *testing.T) {
+	spec := Spec{
+		Paths: map[string]*PathItem{"/a": {Get: &Operation{Responses: map[string]Response{
+			"200": {Content: map[string]MediaTypeObject{"application/json": {Schema: &Schema{Ref: schemaRefPrefix + "A"}}}},
+		}}}},
+		Components: &Components{
+			Schemas: map[string]Schema{
+				"A": {Type: "object", Properties: map[string]*Schema{"b": {Items: &Schema{Ref: schemaRefPrefix + "B"}}}},
+				"B": {AllOf: []*Schema{{Ref: schemaRefPrefix + "C"}}},
+				"C": {Type: "string"},
+				"D": {Type: "object", Properties: map[string]*Schema{"a": {Ref: schemaRefPrefix + "A"}}},
+			},
+			Parameters: map[string]Parameter{"E": {Name: "e", In: "query", Schema: &Schema{Ref: schemaRefPrefix + "E"}}},
+		},
+	}
+	spec.Components.Schemas["E"] = Schema{Type: "integer"}
+
+	pruneUnreachableSchemas(&spec, slog.Default())
+	for _, name := range []string{"A", "B", "C", "E"} {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("expected reachable schema %s to be kept", name)
+		}
+	}
+	if _, ok := spec.Components.Schemas["D"]; ok {
+		t.Error("expected unreachable schema D to be pruned")
+	}
+}
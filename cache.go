@@ -1,6 +1,7 @@
 package openapi
 
 import (
+	"context"
 	"go/ast"
 	"go/parser"
 	"go/token"
@@ -11,156 +12,173 @@ import (
 	"sync"
 )
 
-var (
-	specCache     Spec
-	cacheValid    bool
-	cacheMutex    sync.RWMutex
-	typeIndex     *TypeIndex
-	typeIndexOnce sync.Once
-	modulePath    string // loaded from go.mod to identify internal packages
-)
-
-// Find a way to add method that will add external known types to the type index
-// This is useful for types that are not defined in the current package but are known to the OpenAPI spec,
-// such as types from external libraries or standard library types that we want to document.
-func ensureTypeIndex() {
-	// debug.PrintStack()
-	typeIndexOnce.Do(func() {
-		// load module path for package classification
-		loadModulePath()
-		slog.Debug("[openapi] cache.go: initializing typeIndex and externalKnownTypes")
-		// Build type index once at startup
-		typeIndex = BuildTypeIndex()
-
-		slog.Debug("[openapi] cache.go: typeIndex built, setting externalKnownTypes")
-		typeIndex.externalKnownTypes = map[string]*Schema{
-			// JSON and raw data types
-			"json.RawMessage": {Type: "object", Description: "Raw JSON data", AdditionalProperties: true},
-
-			// PostgreSQL types
-			"pgtype.Numeric":  {Type: "number", Description: "PostgreSQL numeric type"},
-			"pgtype.Interval": {Type: "string", Description: "PostgreSQL interval type"},
-			"pgtype.Timestamptz": {
-				Type:        "string",
-				Format:      "date-time",
-				Description: "PostgreSQL timestamp with timezone",
-			},
-			"pgtype.Timestamp": {Type: "string", Format: "date-time", Description: "PostgreSQL timestamp"},
-			"pgtype.UUID":      {Type: "string", Format: "uuid", Description: "PostgreSQL UUID type"},
-			"pgtype.JSONB":     {Type: "object", Description: "PostgreSQL JSONB type", AdditionalProperties: true},
-			"pgtype.JSON":      {Type: "object", Description: "PostgreSQL JSON type", AdditionalProperties: true},
-
-			// Time types
-			"time.Time": {Type: "string", Format: "date-time", Description: "RFC3339 date-time"},
-			"*time.Time": {
-				OneOf:       []*Schema{{Type: "string", Format: "date-time"}, {Type: "null"}},
-				Description: "Nullable RFC3339 date-time",
-			},
-			"time.Duration": {Type: "string", Description: "Duration string (e.g., '1h30m')"},
-
-			// UUID types
-			"uuid.UUID": {Type: "string", Format: "uuid", Description: "UUID string"},
-			"*uuid.UUID": {
-				OneOf:       []*Schema{{Type: "string", Format: "uuid"}, {Type: "null"}},
-				Description: "Nullable UUID string",
-			},
-
-			// Network types
-			"net.IP":    {Type: "string", Format: "ipv4", Description: "IPv4 address"},
-			"net.IPNet": {Type: "string", Description: "IP network (CIDR notation)"},
-			"url.URL":   {Type: "string", Format: "uri", Description: "URL string"},
-			"*url.URL": {
-				OneOf:       []*Schema{{Type: "string", Format: "uri"}, {Type: "null"}},
-				Description: "Nullable URL string",
-			},
-
-			// Database driver types
-			"sql.NullString": {OneOf: []*Schema{{Type: "string"}, {Type: "null"}}, Description: "Nullable string"},
-			"sql.NullInt64": {
-				OneOf:       []*Schema{{Type: "integer", Format: "int64"}, {Type: "null"}},
-				Description: "Nullable integer",
-			},
-			"sql.NullFloat64": {OneOf: []*Schema{{Type: "number"}, {Type: "null"}}, Description: "Nullable number"},
-			"sql.NullBool":    {OneOf: []*Schema{{Type: "boolean"}, {Type: "null"}}, Description: "Nullable boolean"},
-			"sql.NullTime": {
-				OneOf:       []*Schema{{Type: "string", Format: "date-time"}, {Type: "null"}},
-				Description: "Nullable date-time",
-			},
-
-			// Common Go types that might appear in APIs
-			"big.Int": {Type: "string", Description: "Big integer as string"},
-			"*big.Int": {
-				OneOf:       []*Schema{{Type: "string"}, {Type: "null"}},
-				Description: "Nullable big integer as string",
-			},
-			"decimal.Decimal": {Type: "string", Description: "Decimal number as string"},
-			"*decimal.Decimal": {
-				OneOf:       []*Schema{{Type: "string"}, {Type: "null"}},
-				Description: "Nullable decimal number as string",
-			},
-
-			// Add more external types as needed
-		}
-		// Log the number of types and files indexed
-		slog.Debug(
-			"[openapi] cache.go: typeIndex initialized",
-			"types",
-			len(typeIndex.types),
-			"files",
-			len(typeIndex.files),
-		)
-	})
+// defaultKnownTypes returns the schemas of common external types that are not defined in the
+// project, such as time.Time or pgtype.UUID. Each call returns new schemas, so generators never
+// share them.
+func defaultKnownTypes() map[string]*Schema {
+	return map[string]*Schema{
+		// JSON and raw data types
+		"json.RawMessage": {Type: "object", Description: "Raw JSON data", AdditionalProperties: true},
+
+		// PostgreSQL types
+		"pgtype.Numeric":  {Type: "number", Description: "PostgreSQL numeric type"},
+		"pgtype.Interval": {Type: "string", Description: "PostgreSQL interval type"},
+		"pgtype.Timestamptz": {
+			Type:        "string",
+			Format:      "date-time",
+			Description: "PostgreSQL timestamp with timezone",
+		},
+		"pgtype.Timestamp": {Type: "string", Format: "date-time", Description: "PostgreSQL timestamp"},
+		"pgtype.UUID":      {Type: "string", Format: "uuid", Description: "PostgreSQL UUID type"},
+		"pgtype.JSONB":     {Type: "object", Description: "PostgreSQL JSONB type", AdditionalProperties: true},
+		"pgtype.JSON":      {Type: "object", Description: "PostgreSQL JSON type", AdditionalProperties: true},
+
+		// Time types
+		"time.Time": {Type: "string", Format: "date-time", Description: "RFC3339 date-time"},
+		"*time.Time": {
+			OneOf:       []*Schema{{Type: "string", Format: "date-time"}, {Type: "null"}},
+			Description: "Nullable RFC3339 date-time",
+		},
+		"time.Duration": {Type: "string", Description: "Duration string (e.g., '1h30m')"},
+
+		// UUID types
+		"uuid.UUID": {Type: "string", Format: "uuid", Description: "UUID string"},
+		"*uuid.UUID": {
+			OneOf:       []*Schema{{Type: "string", Format: "uuid"}, {Type: "null"}},
+			Description: "Nullable UUID string",
+		},
+
+		// Network types
+		"net.IP":    {Type: "string", Format: "ipv4", Description: "IPv4 address"},
+		"net.IPNet": {Type: "string", Description: "IP network (CIDR notation)"},
+		"url.URL":   {Type: "string", Format: "uri", Description: "URL string"},
+		"*url.URL": {
+			OneOf:       []*Schema{{Type: "string", Format: "uri"}, {Type: "null"}},
+			Description: "Nullable URL string",
+		},
+
+		// Database driver types
+		"sql.NullString": {OneOf: []*Schema{{Type: "string"}, {Type: "null"}}, Description: "Nullable string"},
+		"sql.NullInt64": {
+			OneOf:       []*Schema{{Type: "integer", Format: "int64"}, {Type: "null"}},
+			Description: "Nullable integer",
+		},
+		"sql.NullFloat64": {OneOf: []*Schema{{Type: "number"}, {Type: "null"}}, Description: "Nullable number"},
+		"sql.NullBool":    {OneOf: []*Schema{{Type: "boolean"}, {Type: "null"}}, Description: "Nullable boolean"},
+		"sql.NullTime": {
+			OneOf:       []*Schema{{Type: "string", Format: "date-time"}, {Type: "null"}},
+			Description: "Nullable date-time",
+		},
+
+		// Common Go types that might appear in APIs
+		"big.Int": {Type: "string", Description: "Big integer as string"},
+		"*big.Int": {
+			OneOf:       []*Schema{{Type: "string"}, {Type: "null"}},
+			Description: "Nullable big integer as string",
+		},
+		"decimal.Decimal": {Type: "string", Description: "Decimal number as string"},
+		"*decimal.Decimal": {
+			OneOf:       []*Schema{{Type: "string"}, {Type: "null"}},
+			Description: "Nullable decimal number as string",
+		},
+
+		// Add more external types as needed
+	}
 }
 
 // TypeIndex provides fast lookup of type definitions by package and type name.
+// An index is read-only once built, so generators may share one.
 type TypeIndex struct {
-	types              map[string]map[string]*ast.TypeSpec // package -> type -> spec
-	files              map[string]*ast.File                // file path -> parsed file
-	externalKnownTypes map[string]*Schema                  // external known types
-	qualifiedTypes     map[string]*ast.TypeSpec            // qualified type name -> spec (e.g., "order.CreateReq")
-	packageImports     map[string]string                   // import path -> package name (e.g., "github.com/user/sqlc" -> "sqlc")
+	types          map[string]map[string]*ast.TypeSpec // package -> type -> spec
+	files          map[string]*ast.File                // file path -> parsed file
+	fset           *token.FileSet                      // positions of the parsed files
+	qualifiedTypes map[string]*ast.TypeSpec            // qualified type name -> spec (e.g., "order.CreateReq")
+	packageImports map[string]string                   // import path -> package name (e.g., "github.com/user/sqlc" -> "sqlc")
+	funcs          map[string][]string                 // function name (e.g., "UserHandler.List") -> declaring files
+	root           string                              // project root the index was built from
+	modulePath     string                              // module path from go.mod, to identify internal packages
 }
 
-// BuildTypeIndex scans the given roots and builds a type index for all Go types.
+// BuildTypeIndex scans the project containing the working directory and builds a type index for
+// all Go types.
 func BuildTypeIndex() *TypeIndex {
+	idx, _ := BuildTypeIndexContext(context.Background())
+	return idx
+}
+
+// BuildTypeIndexContext scans the given directories, or the project containing the working
+// directory when none are given, and builds a type index for all Go types. Package paths are
+// resolved against the go.mod found at or above the first root.
+// It stops early and returns ctx.Err() when the context is cancelled.
+func BuildTypeIndexContext(ctx context.Context, roots ...string) (*TypeIndex, error) {
+	return buildTypeIndex(ctx, slog.Default(), roots...)
+}
+
+// buildTypeIndex implements BuildTypeIndexContext, logging to logger.
+func buildTypeIndex(ctx context.Context, logger *slog.Logger, roots ...string) (*TypeIndex, error) {
 	idx := &TypeIndex{
-		types:              make(map[string]map[string]*ast.TypeSpec),
-		files:              make(map[string]*ast.File),
-		externalKnownTypes: make(map[string]*Schema),
-		qualifiedTypes:     make(map[string]*ast.TypeSpec),
-		packageImports:     make(map[string]string),
+		types:          make(map[string]map[string]*ast.TypeSpec),
+		files:          make(map[string]*ast.File),
+		fset:           token.NewFileSet(),
+		qualifiedTypes: make(map[string]*ast.TypeSpec),
+		packageImports: make(map[string]string),
+		funcs:          make(map[string][]string),
 	}
 
 	// Find project root by looking for go.mod
-	projectRoot := findProjectRoot()
+	var projectRoot string
+	if len(roots) > 0 {
+		projectRoot = findModuleRoot(roots[0])
+	} else {
+		projectRoot = findProjectRoot()
+	}
 	if projectRoot == "" {
-		slog.Debug("[openapi] BuildTypeIndex: could not find project root, using current directory")
+		logger.Debug("[openapi] BuildTypeIndex: could not find project root, using current directory")
 		projectRoot = "."
+		if len(roots) > 0 {
+			projectRoot = roots[0]
+		}
 	} else {
-		slog.Debug("[openapi] BuildTypeIndex: using project root", "root", projectRoot)
+		logger.Debug("[openapi] BuildTypeIndex: using project root", "root", projectRoot)
+	}
+	idx.root = projectRoot
+	idx.modulePath = readModulePath(projectRoot)
+	if len(roots) == 0 {
+		roots = []string{projectRoot}
 	}
 
-	_ = filepath.Walk(projectRoot, func(path string, info os.FileInfo, err error) error {
-		if err != nil ||
-			info.IsDir() ||
-			!strings.HasSuffix(path, ".go") ||
-			strings.HasSuffix(path, "_test.go") {
-			return err
-		}
+	for _, root := range roots {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil ||
+				info.IsDir() ||
+				!strings.HasSuffix(path, ".go") ||
+				strings.HasSuffix(path, "_test.go") {
+				return err
+			}
 
-		return idx.indexFile(path)
-	})
+			return idx.indexFile(path, logger)
+		})
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			logger.Debug("[openapi] BuildTypeIndex: cancelled", "root", root, "error", ctxErr)
+			return nil, ctxErr
+		}
+		if err != nil {
+			logger.Debug("[openapi] BuildTypeIndex: walk failed", "root", root, "error", err)
+		}
+	}
 
-	slog.Debug("[openapi] BuildTypeIndex: completed", "totalPackages", len(idx.types), "totalFiles", len(idx.files))
-	return idx
+	logger.Debug("[openapi] BuildTypeIndex: completed", "totalPackages", len(idx.types), "totalFiles", len(idx.files))
+	return idx, nil
 }
 
 // indexFile processes a single Go file and indexes its types
-func (idx *TypeIndex) indexFile(path string) error {
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+func (idx *TypeIndex) indexFile(path string, logger *slog.Logger) error {
+	file, err := parser.ParseFile(idx.fset, path, nil, parser.ParseComments)
 	if err != nil {
-		slog.Debug("[openapi] BuildTypeIndex: failed to parse file", "path", path, "err", err)
+		logger.Debug("[openapi] BuildTypeIndex: failed to parse file", "path", path, "err", err)
 		return nil // Continue with other files
 	}
 
@@ -183,8 +201,13 @@ func (idx *TypeIndex) indexFile(path string) error {
 		idx.types[pkg] = make(map[string]*ast.TypeSpec)
 	}
 
-	// Index type declarations
+	// Index type and function declarations
 	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			name := funcDeclName(fn)
+			idx.funcs[name] = append(idx.funcs[name], path)
+			continue
+		}
 		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
 			for _, spec := range gd.Specs {
 				if ts, isTypeSpec := spec.(*ast.TypeSpec); isTypeSpec {
@@ -195,7 +218,7 @@ func (idx *TypeIndex) indexFile(path string) error {
 					idx.types[pkg][typeName] = ts
 					idx.qualifiedTypes[qualifiedName] = ts
 
-					slog.Debug(
+					logger.Debug(
 						"[openapi] BuildTypeIndex: indexed type",
 						"package", pkg,
 						"type", typeName,
@@ -210,14 +233,11 @@ func (idx *TypeIndex) indexFile(path string) error {
 	return nil
 }
 
+// GetTypeIndex builds a type index for the current project.
+//
+// Deprecated: Use BuildTypeIndex. There is no shared index; every Generator owns its own.
 func GetTypeIndex() *TypeIndex {
-	if typeIndex == nil {
-		slog.Error("[openapi] GetTypeIndex: typeIndex is nil, building type index")
-		typeIndex = BuildTypeIndex()
-	} else {
-		slog.Debug("[openapi] GetTypeIndex: returning existing typeIndex")
-	}
-	return typeIndex
+	return BuildTypeIndex()
 }
 
 // LookupType returns the TypeSpec for a given package and type name, or nil if not found.
@@ -231,6 +251,58 @@ func (idx *TypeIndex) LookupType(pkg, typeName string) *ast.TypeSpec {
 	return nil
 }
 
+// LookupFuncFile returns the path of the file declaring a function or method.
+// pkgPath is the import path reported by the runtime and recv is the receiver base type name ("" for functions).
+// When several packages declare the same name, the file located in the package's directory wins.
+func (idx *TypeIndex) LookupFuncFile(pkgPath, recv, name string) string {
+	if idx == nil {
+		return ""
+	}
+	declName := name
+	if recv != "" {
+		declName = recv + "." + name
+	}
+	files := idx.funcs[declName]
+	if len(files) == 0 {
+		return ""
+	}
+
+	if dir := idx.packageDir(pkgPath); dir != "" {
+		for _, file := range files {
+			if abs, err := filepath.Abs(file); err == nil && filepath.Dir(abs) == dir {
+				return file
+			}
+		}
+	}
+
+	// Fall back to matching the package name against the last import path element
+	pkgName := pkgPath[strings.LastIndex(pkgPath, "/")+1:]
+	for _, file := range files {
+		if f := idx.files[file]; f != nil && f.Name.Name == pkgName {
+			return file
+		}
+	}
+	if len(files) == 1 {
+		return files[0]
+	}
+	return ""
+}
+
+// packageDir maps an import path inside the current module to its absolute directory.
+func (idx *TypeIndex) packageDir(pkgPath string) string {
+	if idx.modulePath == "" || idx.root == "" {
+		return ""
+	}
+	if pkgPath != idx.modulePath && !strings.HasPrefix(pkgPath, idx.modulePath+"/") {
+		return ""
+	}
+	root, err := filepath.Abs(idx.root)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(pkgPath, idx.modulePath)))
+}
+
 // LookupQualifiedType returns the TypeSpec for a qualified type name (e.g., "order.CreateReq")
 func (idx *TypeIndex) LookupQualifiedType(qualifiedName string) *ast.TypeSpec {
 	if idx == nil {
@@ -276,24 +348,44 @@ func (idx *TypeIndex) GetQualifiedTypeName(typeName string) string {
 	return typeName
 }
 
+// registeredKnownTypes holds the types added with the package-level AddExternalKnownType.
+// Generators copy them when they are created.
+var registeredKnownTypes struct {
+	sync.RWMutex
+	types map[string]*Schema
+}
+
+// AddExternalKnownType registers a schema for an external type with every generator created afterwards.
+//
+// Deprecated: Use Generator.AddExternalKnownType to configure a single generator.
 func AddExternalKnownType(name string, schema *Schema) {
-	ensureTypeIndex() // Ensure typeIndex is initialized
-	if typeIndex == nil {
-		slog.Error("[openapi] AddExternalKnownType: typeIndex is nil, cannot add external type", "name", name)
-		return
-	}
-	if typeIndex.externalKnownTypes == nil {
-		typeIndex.externalKnownTypes = make(map[string]*Schema)
+	registeredKnownTypes.Lock()
+	defer registeredKnownTypes.Unlock()
+	if registeredKnownTypes.types == nil {
+		registeredKnownTypes.types = make(map[string]*Schema)
 	}
-	typeIndex.externalKnownTypes[name] = schema
+	registeredKnownTypes.types[name] = schema
 	slog.Debug("[openapi] AddExternalKnownType: added external known type", "name", name)
 }
 
-// resetTypeIndexForTesting resets the type index for testing purposes
-// This should only be used in tests
-func resetTypeIndexForTesting() {
-	typeIndex = nil
-	typeIndexOnce = sync.Once{}
+// newKnownTypes returns a generator's initial known types: the defaults plus copies of the
+// types registered with AddExternalKnownType.
+func newKnownTypes() map[string]*Schema {
+	types := defaultKnownTypes()
+	registeredKnownTypes.RLock()
+	defer registeredKnownTypes.RUnlock()
+	for name, schema := range registeredKnownTypes.types {
+		copied := *schema
+		types[name] = &copied
+	}
+	return types
+}
+
+// resetKnownTypesForTesting clears the types registered with AddExternalKnownType.
+func resetKnownTypesForTesting() {
+	registeredKnownTypes.Lock()
+	registeredKnownTypes.types = nil
+	registeredKnownTypes.Unlock()
 }
 
 // getQualifiedTypeName creates a qualified type name for indexing.
@@ -314,7 +406,7 @@ func (idx *TypeIndex) isExternalPackage(pkg string) bool {
 	// If an import alias maps to a path outside the current module, treat as external
 	for importPath, alias := range idx.packageImports {
 		if alias == pkg {
-			if modulePath != "" && strings.HasPrefix(importPath, modulePath) {
+			if idx.modulePath != "" && strings.HasPrefix(importPath, idx.modulePath) {
 				return false
 			}
 			return true
@@ -331,6 +423,15 @@ func findProjectRoot() string {
 	if err != nil {
 		return ""
 	}
+	return findModuleRoot(currentDir)
+}
+
+// findModuleRoot returns the nearest directory at or above dir that contains a go.mod file.
+func findModuleRoot(dir string) string {
+	currentDir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
 
 	// Walk up the directory tree looking for go.mod
 	for {
@@ -351,23 +452,19 @@ func findProjectRoot() string {
 	return ""
 }
 
-// loadModulePath reads the Go module path from go.mod to distinguish internal vs external packages
-func loadModulePath() {
-	if modulePath != "" {
-		return
-	}
-	root := findProjectRoot()
+// readModulePath reads the Go module path from the go.mod in root.
+func readModulePath(root string) string {
 	if root == "" {
-		return
+		return ""
 	}
 	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
 	if err != nil {
-		return
+		return ""
 	}
 	for _, line := range strings.Split(string(data), "\n") {
 		if strings.HasPrefix(line, "module ") {
-			modulePath = strings.TrimSpace(strings.TrimPrefix(line, "module "))
-			return
+			return strings.TrimSpace(strings.TrimPrefix(line, "module "))
 		}
 	}
+	return ""
 }
@@ -1,13 +1,17 @@
 package openapi
 
 import (
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/token"
 	"log/slog"
 	"net/http"
-	"reflect"
-	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -17,8 +21,21 @@ import (
 // Generator creates OpenAPI specifications from Chi routers.
 // It provides methods for analyzing route structures, parsing annotations,
 // and generating complete OpenAPI 3.1 specifications.
+//
+// A Generator owns its type index, known types and caches. Calls to GenerateSpec on one
+// Generator are serialized; use separate generators to generate specs in parallel.
 type Generator struct {
 	schemaGen *SchemaGenerator
+	roots     []string    // directories to index when no TypeIndex was given
+	hooks     Hooks       // callbacks run during generation
+	problems  *problemLog // warnings of the current generation in strict mode; nil otherwise
+
+	middlewareDocs *middlewareRegistry // middleware described for this generator only
+
+	mutex    sync.Mutex           // serializes GenerateSpec
+	astMutex sync.Mutex           // guards astCache
+	astCache map[string]*ast.File // handler source files outside the TypeIndex
+	astFset  *token.FileSet       // positions of the files in astCache
 }
 
 // Config defines the configuration for OpenAPI specification generation.
@@ -31,6 +48,21 @@ type Config struct {
 	Server         string   // Optional: Base server URL
 	Contact        *Contact // Optional: Contact information
 	License        *License // Optional: License information
+
+	OperationIDStrategy OperationIDStrategy // Optional: operationId derivation without @ID (default: handler name)
+	DocCommentFallback  bool                // Optional: derive missing @Summary/@Description from the Go doc comment
+
+	SecuritySchemes      map[string]SecurityScheme // Optional: security schemes by name (default: BearerAuth JWT)
+	Security             []SecurityRequirement     // Optional: default requirements for all operations
+	PruneSecuritySchemes bool                      // Optional: drop schemes no requirement references
+
+	IncludeRoutes []RouteRule // Optional: only document routes matching one of these rules
+	ExcludeRoutes []RouteRule // Optional: leave out routes matching any of these rules (nil: DefaultExcludeRoutes)
+
+	// Optional: publish the spec for one audience, e.g. "partner". Operations and struct fields
+	// restricted to other audiences are omitted, and unreachable schemas are pruned.
+	// Empty publishes everything.
+	Audience string
 }
 
 // Contact represents contact information for the API.
@@ -52,7 +84,7 @@ type Spec struct {
 	Info              Info                   `json:"info"`
 	JSONSchemaDialect string                 `json:"jsonSchemaDialect,omitempty"` // OpenAPI 3.1 feature
 	Servers           []Server               `json:"servers,omitempty"`
-	Paths             map[string]PathItem    `json:"paths"`
+	Paths             map[string]*PathItem   `json:"paths"`
 	Webhooks          Webhooks               `json:"webhooks,omitempty"` // OpenAPI 3.1 feature
 	Components        *Components            `json:"components,omitempty"`
 	Tags              []Tag                  `json:"tags,omitempty"`
@@ -74,7 +106,73 @@ type Server struct {
 	Description string `json:"description,omitempty"`
 }
 
-type PathItem map[string]Operation
+// PathItem describes the operations available on a single path.
+// Summary and Description apply to all of them (e.g. from group annotations).
+type PathItem struct {
+	Summary     string     `json:"summary,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Get         *Operation `json:"get,omitempty"`
+	Put         *Operation `json:"put,omitempty"`
+	Post        *Operation `json:"post,omitempty"`
+	Delete      *Operation `json:"delete,omitempty"`
+	Options     *Operation `json:"options,omitempty"`
+	Head        *Operation `json:"head,omitempty"`
+	Patch       *Operation `json:"patch,omitempty"`
+	Trace       *Operation `json:"trace,omitempty"`
+}
+
+// Operation returns the operation for an HTTP method (case-insensitive), or nil.
+func (p *PathItem) Operation(method string) *Operation {
+	if field := p.operationField(method); field != nil {
+		return *field
+	}
+	return nil
+}
+
+// SetOperation sets the operation for an HTTP method (case-insensitive). It reports false for
+// methods OpenAPI path items cannot describe, e.g. CONNECT.
+func (p *PathItem) SetOperation(method string, operation *Operation) bool {
+	field := p.operationField(method)
+	if field == nil {
+		return false
+	}
+	*field = operation
+	return true
+}
+
+// Operations returns the item's operations keyed by lower-case method.
+func (p *PathItem) Operations() map[string]*Operation {
+	operations := make(map[string]*Operation)
+	for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+		if operation := p.Operation(method); operation != nil {
+			operations[method] = operation
+		}
+	}
+	return operations
+}
+
+// operationField returns the field holding the operation for method, or nil if there is none.
+func (p *PathItem) operationField(method string) **Operation {
+	switch strings.ToLower(method) {
+	case "get":
+		return &p.Get
+	case "put":
+		return &p.Put
+	case "post":
+		return &p.Post
+	case "delete":
+		return &p.Delete
+	case "options":
+		return &p.Options
+	case "head":
+		return &p.Head
+	case "patch":
+		return &p.Patch
+	case "trace":
+		return &p.Trace
+	}
+	return nil
+}
 
 type Operation struct {
 	Tags         []string               `json:"tags,omitempty"`
@@ -89,14 +187,70 @@ type Operation struct {
 	Deprecated   bool                   `json:"deprecated,omitempty"`
 	Security     []SecurityRequirement  `json:"security,omitempty"`
 	Servers      []Server               `json:"servers,omitempty"`
+
+	// Extensions holds specification extensions ("x-" fields), e.g. contributed by middleware.
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON encodes the operation with its specification extensions inlined.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type plain Operation
+	data, err := json.Marshal(plain(o))
+	if err != nil || len(o.Extensions) == 0 {
+		return data, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range o.Extensions {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = raw
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON decodes an operation, collecting "x-" fields into Extensions.
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	type plain Operation
+	if err := json.Unmarshal(data, (*plain)(o)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for key, raw := range fields {
+		if !strings.HasPrefix(key, "x-") {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		if o.Extensions == nil {
+			o.Extensions = make(map[string]interface{})
+		}
+		o.Extensions[key] = value
+	}
+	return nil
 }
 
 type Parameter struct {
-	Name        string  `json:"name"`
-	In          string  `json:"in"`
-	Description string  `json:"description,omitempty"`
-	Required    bool    `json:"required,omitempty"`
-	Schema      *Schema `json:"schema,omitempty"`
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Deprecated  bool        `json:"deprecated,omitempty"`
+	Style       string      `json:"style,omitempty"`
+	Explode     *bool       `json:"explode,omitempty"`
+	Schema      *Schema     `json:"schema,omitempty"`
+	Example     interface{} `json:"example,omitempty"`
 }
 
 type RequestBody struct {
@@ -241,11 +395,33 @@ type Webhooks map[string]*PathItem
 // SecurityRequirement represents a security requirement
 type SecurityRequirement map[string][]string
 
+// SecurityScheme describes an authentication mechanism. Type is one of "apiKey", "http",
+// "oauth2", "openIdConnect" or "mutualTLS"; the remaining fields apply to specific types.
 type SecurityScheme struct {
-	Type         string `json:"type"`
-	Scheme       string `json:"scheme,omitempty"`
-	BearerFormat string `json:"bearerFormat,omitempty"`
-	Description  string `json:"description,omitempty"`
+	Type             string      `json:"type"`
+	Description      string      `json:"description,omitempty"`
+	Name             string      `json:"name,omitempty"`             // apiKey: header, query or cookie name
+	In               string      `json:"in,omitempty"`               // apiKey: "header", "query" or "cookie"
+	Scheme           string      `json:"scheme,omitempty"`           // http: e.g. "bearer" or "basic"
+	BearerFormat     string      `json:"bearerFormat,omitempty"`     // http bearer: e.g. "JWT"
+	Flows            *OAuthFlows `json:"flows,omitempty"`            // oauth2
+	OpenIDConnectURL string      `json:"openIdConnectUrl,omitempty"` // openIdConnect: discovery URL
+}
+
+// OAuthFlows lists the OAuth2 flows supported by a security scheme.
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+}
+
+// OAuthFlow configures one OAuth2 flow; Scopes maps scope names to descriptions.
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
 }
 
 type Tag struct {
@@ -253,19 +429,83 @@ type Tag struct {
 	Description string `json:"description,omitempty"`
 }
 
+// NewGeneratorWithCache creates a Generator that uses an existing TypeIndex.
+// The index is only read, so several generators may share it.
+// It is equivalent to NewGenerator(WithTypeIndex(typeIndex)).
 func NewGeneratorWithCache(typeIndex *TypeIndex) *Generator {
+	return NewGenerator(WithTypeIndex(typeIndex))
+}
+
+// NewGenerator creates a Generator configured by opts. Unless WithTypeIndex is given, the
+// generator indexes the current project (or the WithRoots directories) on its first GenerateSpec.
+//
+//	gen := openapi.NewGenerator(
+//		openapi.WithRoots("./cmd/api", "./internal"),
+//		openapi.WithLogger(logger),
+//		openapi.WithSchemaNaming(openapi.ShortSchemaNames),
+//		openapi.WithStrict(),
+//	)
+func NewGenerator(opts ...Option) *Generator {
+	var o generatorOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	logger := o.logger
+	var problems *problemLog
+	if o.strict {
+		if logger == nil {
+			logger = slog.Default()
+		}
+		problems = &problemLog{}
+		logger = slog.New(problemHandler{Handler: logger.Handler(), problems: problems})
+	}
+
+	middlewareDocs := &middlewareRegistry{}
+	for _, entry := range o.middlewareDocs {
+		middlewareDocs.describe(entry.mw, entry.doc)
+	}
+
 	return &Generator{
 		schemaGen: &SchemaGenerator{
-			schemas:   make(map[string]*Schema),
-			typeIndex: typeIndex,
+			schemas:    make(map[string]*Schema),
+			knownTypes: o.knownTypes(),
+			typeIndex:  o.typeIndex,
+			logger:     logger,
+			naming:     o.naming,
 		},
+		roots:    o.roots,
+		hooks:    o.hooks,
+		problems: problems,
+		astCache: make(map[string]*ast.File),
+		astFset:  token.NewFileSet(),
+
+		middlewareDocs: middlewareDocs,
 	}
 }
 
-// NewGenerator creates a Generator with a default TypeIndex.
-func NewGenerator() *Generator {
-	ensureTypeIndex()
-	return NewGeneratorWithCache(typeIndex)
+// loadTypeIndex builds the generator's TypeIndex on first use.
+func (g *Generator) loadTypeIndex(ctx context.Context) error {
+	if g.schemaGen.typeIndex != nil {
+		return nil
+	}
+	idx, err := buildTypeIndex(ctx, g.log(), g.roots...)
+	if err != nil {
+		return err
+	}
+	g.schemaGen.typeIndex = idx
+	return nil
+}
+
+// log returns the generator's logger.
+func (g *Generator) log() *slog.Logger {
+	return g.schemaGen.log()
+}
+
+// AddExternalKnownType registers the schema this generator uses for an external type,
+// e.g. "decimal.Decimal". It does not affect other generators.
+func (g *Generator) AddExternalKnownType(name string, schema *Schema) {
+	g.schemaGen.AddExternalKnownType(name, schema)
 }
 
 // GenerateSpec creates an OpenAPI 3.1 specification from a Chi router.
@@ -277,12 +517,35 @@ func NewGenerator() *Generator {
 //   - Version: The API version
 //
 // The method will log warnings for any parsing errors but will continue generation.
+// Use GenerateSpecContext to cancel generation or to get strict mode errors.
 func (g *Generator) GenerateSpec(router chi.Router, cfg Config) Spec {
+	spec, err := g.GenerateSpecContext(context.Background(), router, cfg)
+	if err != nil {
+		g.log().Error("[openapi] GenerateSpec: generation failed", "error", err)
+	}
+	return spec
+}
+
+// GenerateSpecContext is like GenerateSpec but stops indexing and route processing when ctx is
+// cancelled, returning ctx.Err(). In strict mode (WithStrict) it returns the spec together with
+// a *StrictModeError listing the warnings logged during generation.
+func (g *Generator) GenerateSpecContext(ctx context.Context, router chi.Router, cfg Config) (Spec, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.problems != nil {
+		g.problems.reset()
+	}
 	if cfg.Title == "" || cfg.Version == "" {
-		slog.Warn("[openapi] GenerateSpec: missing required config", "title", cfg.Title, "version", cfg.Version)
+		g.log().Warn("[openapi] GenerateSpec: missing required config", "title", cfg.Title, "version", cfg.Version)
+	}
+	if err := g.loadTypeIndex(ctx); err != nil {
+		g.log().Debug("[openapi] GenerateSpec: indexing stopped", "error", err)
+		return Spec{}, err
 	}
 
-	slog.Debug("[openapi] GenerateSpec: called", "title", cfg.Title, "version", cfg.Version)
+	g.log().Debug("[openapi] GenerateSpec: called", "title", cfg.Title, "version", cfg.Version, "audience", cfg.Audience)
+	g.schemaGen.setAudience(cfg.Audience)
 	spec := Spec{
 		OpenAPI:           "3.1.0",
 		JSONSchemaDialect: "https://spec.openapis.org/oas/3.1/dialect/base",
@@ -294,7 +557,7 @@ func (g *Generator) GenerateSpec(router chi.Router, cfg Config) Spec {
 			Contact:        cfg.Contact,
 			License:        cfg.License,
 		},
-		Paths: make(map[string]PathItem),
+		Paths: make(map[string]*PathItem),
 		Components: &Components{
 			Schemas:         make(map[string]Schema),
 			SecuritySchemes: make(map[string]SecurityScheme),
@@ -311,54 +574,109 @@ func (g *Generator) GenerateSpec(router chi.Router, cfg Config) Spec {
 
 	// Add server if configured
 	if cfg.Server != "" {
-		slog.Debug("[openapi] GenerateSpec: adding server", "server", cfg.Server)
+		g.log().Debug("[openapi] GenerateSpec: adding server", "server", cfg.Server)
 		spec.Servers = []Server{{URL: cfg.Server, Description: "API Server"}}
 	}
 
-	slog.Debug("[openapi] GenerateSpec: adding security scheme")
-	// Add standard security scheme
-	spec.Components.SecuritySchemes["BearerAuth"] = SecurityScheme{
-		Type:         "http",
-		Scheme:       "bearer",
-		BearerFormat: "JWT",
-		Description:  "JWT token authentication",
-	}
+	g.log().Debug("[openapi] GenerateSpec: adding security schemes")
+	addSecuritySchemes(&spec, cfg)
 
 	// Add standard schemas
 	g.addStandardSchemas(&spec)
 
-	// Discover routes via DiscoverRoutes
+	// Discover routes and apply the include and exclude rules
 	tags := make(map[string]bool)
-	routes, err := DiscoverRoutes(router)
+	routes, err := InspectRoutes(router)
 	if err != nil {
-		slog.Warn("[openapi] GenerateSpec: InspectRoutes error", "error", err)
+		g.log().Warn("[openapi] GenerateSpec: InspectRoutes error", "error", err)
 	}
+	routes = filterRoutes(routes, cfg.IncludeRoutes, excludeRules(cfg), g.log())
+	// chi walks its routes in map order; sort them so operationId suffixes are stable
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	operationIDs := newOperationIDRegistry(g.log())
 	for _, ri := range routes {
-		method := ri.Method
-		route := ri.Pattern
-		handler := ri.HandlerFunc
-		slog.Debug("[openapi] GenerateSpec: processing route", "method", method, "route", route)
+		if err := ctx.Err(); err != nil {
+			g.log().Debug("[openapi] GenerateSpec: cancelled", "error", err)
+			return Spec{}, err
+		}
+		var handler http.Handler = ri.HandlerFunc
+		if ri.Handler != nil {
+			handler = ri.Handler
+		}
+		handlerInfo, annotations := g.handlerAnnotations(handler)
+		if cfg.DocCommentFallback && annotations != nil {
+			applyDocCommentFallback(annotations, handlerInfo.FunctionName)
+		}
+		groups := g.groupAnnotations(ri.Middlewares)
+		if len(groups) > 0 {
+			annotations = mergeGroupAnnotations(annotations, groups)
+		}
+		if annotations != nil && annotations.Hidden {
+			g.log().Debug("[openapi] GenerateSpec: skipping hidden route", "method", ri.Method, "pattern", ri.Pattern)
+			continue
+		}
+		if annotations != nil && !visibleTo(annotations.Audiences, cfg.Audience) {
+			g.log().Debug("[openapi] GenerateSpec: skipping route for other audiences", "method", ri.Method, "pattern", ri.Pattern, "audiences", annotations.Audiences)
+			continue
+		}
+
+		// @Router overrides the published path and method
+		route, method := ri.Pattern, ri.Method
+		if annotations != nil && annotations.Router != nil {
+			route = annotations.Router.Path
+			if annotations.Router.Method != "" {
+				method = annotations.Router.Method
+			}
+		}
+		g.log().Debug("[openapi] GenerateSpec: processing route", "method", method, "route", route, "pattern", ri.Pattern)
 		pathKey := convertRouteToOpenAPIPath(route)
-		operation := g.buildOperation(handler, route, method, ri.Middlewares)
+		item := spec.Paths[pathKey]
+		if item == nil {
+			item = &PathItem{}
+		}
+		// chi's Handle and HandleFunc also register CONNECT, which OpenAPI cannot describe
+		if item.operationField(method) == nil {
+			g.log().Debug("[openapi] GenerateSpec: method cannot be described by OpenAPI, skipping", "method", method, "path", pathKey)
+			continue
+		}
+		if item.Operation(method) != nil {
+			g.log().Warn("[openapi] GenerateSpec: duplicate operation, keeping the first", "method", method, "path", pathKey, "pattern", ri.Pattern)
+			continue
+		}
 
-		if spec.Paths[pathKey] == nil {
-			spec.Paths[pathKey] = make(PathItem)
+		operation := g.buildOperation(annotations, route, method, ri.Middlewares)
+		operation.OperationID = operationIDs.assign(cfg.OperationIDStrategy, handlerInfo, annotations, method, pathKey)
+		if g.hooks.OnOperation != nil {
+			g.hooks.OnOperation(ri, &operation)
 		}
-		spec.Paths[pathKey][strings.ToLower(method)] = operation
+		item.SetOperation(method, &operation)
+		if description := groupDescription(groups); description != "" && item.Description == "" {
+			item.Description = description
+		}
+		spec.Paths[pathKey] = item
 		for _, tag := range operation.Tags {
 			tags[tag] = true
 		}
 	}
 
-	slog.Debug("[openapi] GenerateSpec: building tags array")
+	g.log().Debug("[openapi] GenerateSpec: building tags array")
 	// Build tags array
 	spec.Tags = g.buildTags(tags)
 
-	// Add generated schemas with qualified names
+	finalizeSecuritySchemes(&spec, cfg.PruneSecuritySchemes, g.log())
+
+	// Add generated schemas with qualified names, then assign their component names
+	generated := make(map[string]bool)
 	for name, schema := range g.schemaGen.GetSchemas() {
 		// Ensure the schema key is qualified
 		qualifiedName := name
-		slog.Debug(
+		g.log().Debug(
 			"[openapi] GenerateSpec: processing schema",
 			"name",
 			name,
@@ -368,7 +686,7 @@ func (g *Generator) GenerateSpec(router chi.Router, cfg Config) Spec {
 		if !strings.Contains(name, ".") && g.schemaGen.typeIndex != nil {
 			if qualified := g.schemaGen.typeIndex.GetQualifiedTypeName(name); qualified != name {
 				qualifiedName = qualified
-				slog.Debug(
+				g.log().Debug(
 					"[openapi] GenerateSpec: qualifying schema key",
 					"original",
 					name,
@@ -378,44 +696,84 @@ func (g *Generator) GenerateSpec(router chi.Router, cfg Config) Spec {
 			}
 		}
 		spec.Components.Schemas[qualifiedName] = schema
+		generated[qualifiedName] = true
+	}
+	g.nameComponentSchemas(&spec, generated)
+	if cfg.Audience != "" {
+		pruneUnreachableSchemas(&spec, g.log())
+	}
+	if g.hooks.OnSpec != nil {
+		g.hooks.OnSpec(&spec)
 	}
 
-	slog.Debug("[openapi] GenerateSpec: completed", "path_count", len(spec.Paths))
-	return spec
+	g.log().Debug("[openapi] GenerateSpec: completed", "path_count", len(spec.Paths))
+	if g.problems != nil {
+		if problems := g.problems.reset(); len(problems) > 0 {
+			return spec, &StrictModeError{Messages: problems}
+		}
+	}
+	return spec, nil
+}
+
+// nameComponentSchemas renames the component schemas, which are keyed by qualified name during
+// generation, and every reference to them. The OnSchema hook sees the generated schemas under
+// their final names.
+func (g *Generator) nameComponentSchemas(spec *Spec, generated map[string]bool) {
+	qualifiedNames := make([]string, 0, len(spec.Components.Schemas))
+	for qualifiedName := range spec.Components.Schemas {
+		qualifiedNames = append(qualifiedNames, qualifiedName)
+	}
+	names := g.schemaGen.componentNames(qualifiedNames)
+
+	schemas := make(map[string]Schema, len(spec.Components.Schemas))
+	for qualifiedName, schema := range spec.Components.Schemas {
+		name := names[qualifiedName]
+		if generated[qualifiedName] && g.hooks.OnSchema != nil {
+			g.hooks.OnSchema(name, &schema)
+		}
+		schemas[name] = schema
+	}
+	spec.Components.Schemas = schemas
+	if g.schemaGen.naming != nil {
+		renameSchemaRefs(spec, names)
+	}
+}
+
+// handlerAnnotations resolves a route handler's declaration and parses its annotations.
+// Either result may be nil when the handler cannot be resolved or is undocumented.
+func (g *Generator) handlerAnnotations(handler http.Handler) (*HandlerInfo, *Annotation) {
+	handlerInfo := g.extractHandlerInfo(handler)
+	if handlerInfo == nil || handlerInfo.File == "" {
+		return handlerInfo, nil
+	}
+
+	g.log().Debug(
+		"[openapi] handlerAnnotations: parsing annotations",
+		"file",
+		handlerInfo.File,
+		"function",
+		handlerInfo.DeclName(),
+	)
+	annotations, err := g.parseHandlerAnnotations(handlerInfo)
+	if err != nil {
+		g.log().Warn("[openapi] handlerAnnotations: annotations parse error", "error", err)
+	}
+	return handlerInfo, annotations
 }
 
 // buildOperation creates an OpenAPI operation from a handler.
 func (g *Generator) buildOperation(
-	handler http.Handler,
+	annotations *Annotation,
 	route, method string,
 	middlewares []func(http.Handler) http.Handler,
 ) Operation {
-	slog.Debug("[openapi] buildOperation: called", "route", route, "method", method)
-	// Get handler info
-	handlerInfo := g.extractHandlerInfo(handler)
-
-	// Parse annotations if handler info is available
-	var annotations *Annotation
-	if handlerInfo != nil && handlerInfo.File != "" {
-		slog.Debug(
-			"buildOperation: parsing annotations",
-			"file",
-			handlerInfo.File,
-			"function",
-			handlerInfo.FunctionName,
-		)
-		var err error
-		annotations, err = ParseAnnotations(handlerInfo.File, handlerInfo.FunctionName)
-		if err != nil {
-			slog.Warn("[openapi] buildOperation: annotations parse error", "error", err)
-		}
-	}
+	g.log().Debug("[openapi] buildOperation: called", "route", route, "method", method)
 
 	// Build operation
 	operation := Operation{
-		OperationID: generateOperationID(method, route),
+		OperationID: generateOperationID(method, convertRouteToOpenAPIPath(route)),
 		Parameters:  []Parameter{}, // Start with empty parameters, will add from route and annotations
-		Responses:   g.buildResponses(annotations),
+		Responses:   g.buildResponses(method, annotations),
 	}
 
 	// Add path parameters from route
@@ -430,22 +788,27 @@ func (g *Generator) buildOperation(
 
 		// Convert and add parameters from annotations
 		for _, param := range annotations.Parameters {
-			// Skip body parameters - they should be handled as request body, not parameters
-			if param.In == "body" {
+			// Skip body and form parameters - they should be handled as request body, not parameters
+			if param.In == "body" || param.In == "formData" {
 				continue
 			}
 
-			// For non-body parameters (query, header, path), use simple type mapping
-			operation.Parameters = append(operation.Parameters, Parameter{
-				Name:        param.Name,
-				In:          param.In,
-				Description: param.Description,
-				Required:    param.Required,
-				Schema:      &Schema{Type: mapGoTypeToOpenAPI(param.Type)},
-			})
+			if param.Name == "" {
+				operation.Parameters = append(operation.Parameters, g.expandStructParameters(param)...)
+				continue
+			}
+			if param.In == "path" {
+				operation.Parameters = mergePathParameter(operation.Parameters, g.buildParameter(param))
+				continue
+			}
+			operation.Parameters = append(operation.Parameters, g.buildParameter(param))
 		}
 	}
 
+	if annotations != nil && annotations.Deprecation != nil {
+		applyDeprecation(&operation, annotations.Deprecation)
+	}
+
 	// Add default tag if none specified
 	if len(operation.Tags) == 0 {
 		operation.Tags = []string{extractResourceFromRoute(route)}
@@ -456,91 +819,64 @@ func (g *Generator) buildOperation(
 		operation.RequestBody = g.buildRequestBody(annotations)
 	}
 
-	// Determine security requirements
-	if hasJWTMiddleware(middlewares) {
-		operation.Security = []SecurityRequirement{{"BearerAuth": {}}}
+	// Determine security requirements: @Security annotations override middleware documentation
+	if annotations != nil && len(annotations.Security) > 0 {
+		operation.Security = annotations.Security
 	}
+	g.applyMiddlewareDocs(&operation, middlewares, annotations)
 
-	slog.Debug("[openapi] buildOperation: completed", "operationId", operation.OperationID)
+	g.log().Debug("[openapi] buildOperation: completed", "operationId", operation.OperationID)
 	return operation
 }
 
-type HandlerInfo struct {
-	File         string
-	FunctionName string
-	Package      string
-}
-
-// extractHandlerInfo gets information about a handler function.
-func (g *Generator) extractHandlerInfo(handler http.Handler) *HandlerInfo {
-	slog.Debug("[openapi] extractHandlerInfo: called")
-	handlerValue := reflect.ValueOf(handler)
-	if handlerValue.Kind() != reflect.Func {
-		return nil
-	}
-
-	pc := handlerValue.Pointer()
-	funcInfo := runtime.FuncForPC(pc)
-	if funcInfo == nil {
-		return nil
-	}
-
-	file, _ := funcInfo.FileLine(pc)
-	name := funcInfo.Name()
-
-	// Extract function name from full name
-	if lastDot := strings.LastIndex(name, "."); lastDot != -1 {
-		name = name[lastDot+1:]
-		// Remove -fm suffix if present
-		name = strings.TrimSuffix(name, "-fm")
-	}
-
-	slog.Debug("[openapi] extractHandlerInfo: found handler info", "file", file, "function", name)
-	return &HandlerInfo{
-		File:         file,
-		FunctionName: name,
-	}
-}
-
 // buildResponses creates response definitions.
-func (g *Generator) buildResponses(annotations *Annotation) map[string]Response {
-	slog.Debug("[openapi] buildResponses: called")
+// Without @Success annotations a method-aware default is used: 204 for DELETE, 201 for POST, 200 otherwise.
+func (g *Generator) buildResponses(method string, annotations *Annotation) map[string]Response {
+	g.log().Debug("[openapi] buildResponses: called", "method", method)
 	responses := make(map[string]Response)
 
-	// Add success response
-	if annotations != nil && annotations.Success != nil {
-		statusCode := strconv.Itoa(annotations.Success.StatusCode)
-		responses[statusCode] = Response{
-			Description: annotations.Success.Description,
-			Content: map[string]MediaTypeObject{
-				"application/json": {
-					Schema: g.generateResponseSchema(annotations.Success.DataType),
-				},
-			},
+	// Add success responses
+	if annotations != nil && len(annotations.Successes) > 0 {
+		for _, success := range annotations.Successes {
+			response := Response{Description: success.Description}
+			if response.Description == "" {
+				response.Description = statusDescription(success.StatusCode)
+			}
+			if success.DataType != "" {
+				response.Content = mediaContent(
+					responseMediaTypes(annotations),
+					g.generateResponseSchema(success.DataType),
+				)
+			}
+			responses[responseKey(success.StatusCode)] = response
 		}
 	} else {
-		responses["200"] = Response{
-			Description: "Successful response",
-			Content: map[string]MediaTypeObject{
-				"application/json": {
-					Schema: &Schema{Type: "object"},
-				},
-			},
-		}
+		code, response := defaultSuccessResponse(method, responseMediaTypes(annotations))
+		responses[code] = response
 	}
 
 	// Add error responses from annotations
 	if annotations != nil {
 		for _, failure := range annotations.Failures {
-			statusCode := strconv.Itoa(failure.StatusCode)
-			responses[statusCode] = Response{
-				Description: failure.Description,
-				Content: map[string]MediaTypeObject{
-					"application/problem+json": {
-						Schema: &Schema{Ref: "#/components/schemas/ProblemDetails"},
-					},
-				},
+			response := g.buildErrorResponse(failure, annotations)
+			responses[responseKey(failure.StatusCode)] = response
+		}
+	}
+
+	// Attach @Header annotations to the documented responses
+	if annotations != nil {
+		for _, header := range annotations.Headers {
+			key := responseKey(header.StatusCode)
+			response, exists := responses[key]
+			if !exists {
+				g.log().Warn("[openapi] buildResponses: @Header for undocumented response", "status", key, "header", header.Name)
+				continue
 			}
+			AddResponseHeader(&response, header.Name, Header{
+				Description: header.Description,
+				Schema:      g.paramSchema(header.Type),
+			})
+			responses[key] = response
 		}
 	}
 
@@ -550,7 +886,7 @@ func (g *Generator) buildResponses(annotations *Annotation) map[string]Response
 			Description: "Bad Request",
 			Content: map[string]MediaTypeObject{
 				"application/problem+json": {
-					Schema: &Schema{Ref: "#/components/schemas/ProblemDetails"},
+					Schema: g.schemaGen.schemaRef("ProblemDetails"),
 				},
 			},
 		},
@@ -558,7 +894,7 @@ func (g *Generator) buildResponses(annotations *Annotation) map[string]Response
 			Description: "Unauthorized",
 			Content: map[string]MediaTypeObject{
 				"application/problem+json": {
-					Schema: &Schema{Ref: "#/components/schemas/ProblemDetails"},
+					Schema: g.schemaGen.schemaRef("ProblemDetails"),
 				},
 			},
 		},
@@ -566,7 +902,7 @@ func (g *Generator) buildResponses(annotations *Annotation) map[string]Response
 			Description: "Internal Server Error",
 			Content: map[string]MediaTypeObject{
 				"application/problem+json": {
-					Schema: &Schema{Ref: "#/components/schemas/ProblemDetails"},
+					Schema: g.schemaGen.schemaRef("ProblemDetails"),
 				},
 			},
 		},
@@ -578,13 +914,140 @@ func (g *Generator) buildResponses(annotations *Annotation) map[string]Response
 		}
 	}
 
-	slog.Debug("[openapi] buildResponses: completed", "response_count", len(responses))
+	g.log().Debug("[openapi] buildResponses: completed", "response_count", len(responses))
 	return responses
 }
 
+// applyDeprecation marks an operation as deprecated, appends the sunset date and replacement to its
+// description and documents the Deprecation and Sunset headers on every response.
+func applyDeprecation(operation *Operation, deprecation *DeprecationAnnotation) {
+	operation.Deprecated = true
+
+	var notes []string
+	if deprecation.Sunset != "" {
+		notes = append(notes, "Sunset on "+deprecation.Sunset+".")
+	}
+	if deprecation.Replacement != "" {
+		notes = append(notes, capitalize(strings.TrimSuffix(deprecation.Replacement, "."))+".")
+	}
+	if len(notes) > 0 {
+		note := "Deprecated: " + strings.Join(notes, " ")
+		if operation.Description != "" {
+			note = operation.Description + "\n\n" + note
+		}
+		operation.Description = note
+	}
+
+	var sunsetExample string
+	if sunset, err := time.Parse(time.DateOnly, deprecation.Sunset); err == nil {
+		sunsetExample = sunset.UTC().Format(http.TimeFormat)
+	}
+	for code, response := range operation.Responses {
+		AddResponseHeader(&response, "Deprecation", Header{
+			Description: "Signals that the operation is deprecated (RFC 9745)",
+			Schema:      &Schema{Type: "string"},
+		})
+		if sunsetExample != "" {
+			AddResponseHeader(&response, "Sunset", Header{
+				Description: "Date after which the operation may be removed (RFC 8594)",
+				Schema:      &Schema{Type: "string"},
+				Example:     sunsetExample,
+			})
+		}
+		operation.Responses[code] = response
+	}
+}
+
+// buildErrorResponse creates the response for an @Failure annotation.
+// ProblemDetails (or an omitted type) uses the standard RFC 9457 schema; any other type is
+// generated like a success body and served with the operation's @Produce media types.
+func (g *Generator) buildErrorResponse(failure ErrorResponse, annotations *Annotation) Response {
+	response := Response{Description: failure.Description}
+	if response.Description == "" {
+		response.Description = statusDescription(failure.StatusCode)
+	}
+
+	if failure.Type == "" || failure.Type == "ProblemDetails" {
+		response.Content = map[string]MediaTypeObject{
+			"application/problem+json": {
+				Schema: g.schemaGen.schemaRef("ProblemDetails"),
+			},
+		}
+		return response
+	}
+
+	response.Content = mediaContent(responseMediaTypes(annotations), g.generateResponseSchema(failure.Type))
+	return response
+}
+
+// responseMediaTypes returns the media types declared by @Produce, defaulting to application/json.
+func responseMediaTypes(annotations *Annotation) []string {
+	if annotations == nil || len(annotations.Produce) == 0 {
+		return []string{"application/json"}
+	}
+	return annotations.Produce
+}
+
+// requestMediaTypes returns the media types declared by @Accept, defaulting to application/json.
+func requestMediaTypes(annotations *Annotation) []string {
+	if annotations == nil || len(annotations.Accept) == 0 {
+		return []string{"application/json"}
+	}
+	return annotations.Accept
+}
+
+// mediaContent builds a Content map serving the same schema under each media type.
+func mediaContent(mediaTypes []string, schema *Schema) map[string]MediaTypeObject {
+	content := make(map[string]MediaTypeObject, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = MediaTypeObject{Schema: schema}
+	}
+	return content
+}
+
+// defaultSuccessResponse returns the success response assumed for an undocumented operation.
+func defaultSuccessResponse(method string, mediaTypes []string) (string, Response) {
+	switch method {
+	case http.MethodDelete:
+		return "204", Response{Description: "No Content"}
+	case http.MethodPost:
+		return "201", Response{
+			Description: "Created",
+			Content:     mediaContent(mediaTypes, &Schema{Type: "object"}),
+		}
+	default:
+		return "200", Response{
+			Description: "Successful response",
+			Content:     mediaContent(mediaTypes, &Schema{Type: "object"}),
+		}
+	}
+}
+
+// responseKey returns the responses map key for a status code, mapping DefaultStatusCode to "default".
+func responseKey(statusCode int) string {
+	if statusCode == DefaultStatusCode {
+		return "default"
+	}
+	return strconv.Itoa(statusCode)
+}
+
+// statusDescription returns a fallback description for a status code.
+func statusDescription(statusCode int) string {
+	if statusCode == DefaultStatusCode {
+		return "Default response"
+	}
+	if text := http.StatusText(statusCode); text != "" {
+		return text
+	}
+	return "Response " + strconv.Itoa(statusCode)
+}
+
 // buildRequestBody creates request body definition.
 func (g *Generator) buildRequestBody(annotations *Annotation) *RequestBody {
-	slog.Debug("[openapi] buildRequestBody: called")
+	g.log().Debug("[openapi] buildRequestBody: called")
+	if hasFormParams(annotations) {
+		return g.buildFormRequestBody(annotations)
+	}
 	var schema *Schema
 	description := "Request body"
 
@@ -592,7 +1055,7 @@ func (g *Generator) buildRequestBody(annotations *Annotation) *RequestBody {
 	if annotations != nil {
 		for _, param := range annotations.Parameters {
 			if param.In == "body" {
-				slog.Debug("[openapi] buildRequestBody: found body parameter", "type", param.Type)
+				g.log().Debug("[openapi] buildRequestBody: found body parameter", "type", param.Type)
 				// Generate proper schema for the request body type
 				schema = g.schemaGen.GenerateSchema(param.Type)
 				if param.Description != "" {
@@ -605,22 +1068,20 @@ func (g *Generator) buildRequestBody(annotations *Annotation) *RequestBody {
 
 	// Default schema if no annotation provided
 	if schema == nil {
-		slog.Debug("[openapi] buildRequestBody: no body parameter found, using default object schema")
+		g.log().Debug("[openapi] buildRequestBody: no body parameter found, using default object schema")
 		schema = &Schema{Type: "object"}
 	}
 
 	return &RequestBody{
 		Description: description,
 		Required:    true,
-		Content: map[string]MediaTypeObject{
-			"application/json": {Schema: schema},
-		},
+		Content:     mediaContent(requestMediaTypes(annotations), schema),
 	}
 }
 
 // generateResponseSchema creates a response schema.
 func (g *Generator) generateResponseSchema(dataType string) *Schema {
-	slog.Debug("[openapi] generateResponseSchema: called", "dataType", dataType)
+	g.log().Debug("[openapi] generateResponseSchema: called", "dataType", dataType)
 	if dataType == "" {
 		return &Schema{Type: "object"}
 	}
@@ -645,7 +1106,7 @@ func (g *Generator) generateResponseSchema(dataType string) *Schema {
 
 // addStandardSchemas adds predefined schemas.
 func (g *Generator) addStandardSchemas(spec *Spec) {
-	slog.Debug("[openapi] addStandardSchemas: adding ProblemDetails schema")
+	g.log().Debug("[openapi] addStandardSchemas: adding ProblemDetails schema")
 	spec.Components.Schemas["ProblemDetails"] = Schema{
 		Type: "object",
 		Properties: map[string]*Schema{
@@ -664,7 +1125,7 @@ func (g *Generator) addStandardSchemas(spec *Spec) {
 
 // buildTags creates tags array from collected tag names.
 func (g *Generator) buildTags(tagNames map[string]bool) []Tag {
-	slog.Debug("[openapi] buildTags: called", "tag_count", len(tagNames))
+	g.log().Debug("[openapi] buildTags: called", "tag_count", len(tagNames))
 	var tags []Tag
 	for name := range tagNames {
 		tags = append(tags, Tag{
@@ -788,28 +1249,28 @@ func MarkSchemaWriteOnly(schema *Schema) {
 // Helper functions for OpenAPI generation
 
 // convertRouteToOpenAPIPath converts Chi route to OpenAPI path format.
+// Regex constraints are dropped from the template and a trailing "*" becomes a named parameter.
 func convertRouteToOpenAPIPath(route string) string {
-	// Chi uses {param} format, which is the same as OpenAPI
-	return route
+	path, _ := parseRoutePattern(route)
+	return path
 }
 
 // extractPathParameters extracts path parameters from route.
 func extractPathParameters(route string) []Parameter {
-	var params []Parameter
-	parts := strings.Split(route, "/")
-
-	for _, part := range parts {
-		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
-			paramName := strings.Trim(part, "{}")
-			params = append(params, Parameter{
-				Name:     paramName,
-				In:       "path",
-				Required: true,
-				Schema:   &Schema{Type: "string"},
-			})
+	_, routeParams := parseRoutePattern(route)
+	params := make([]Parameter, 0, len(routeParams))
+	for _, rp := range routeParams {
+		param := Parameter{
+			Name:     rp.name,
+			In:       "path",
+			Required: true,
+			Schema:   rp.schema(),
 		}
+		if rp.wildcard {
+			param.Description = "Remainder of the request path"
+		}
+		params = append(params, param)
 	}
-
 	return params
 }
 
@@ -841,19 +1302,6 @@ func extractResourceFromRoute(route string) string {
 	return "default"
 }
 
-// hasJWTMiddleware checks if JWT middleware is present.
-func hasJWTMiddleware(middlewares []func(http.Handler) http.Handler) bool {
-	for _, mw := range middlewares {
-		funcName := runtime.FuncForPC(reflect.ValueOf(mw).Pointer()).Name()
-		if strings.Contains(funcName, "jwt") ||
-			strings.Contains(funcName, "JWT") ||
-			strings.Contains(funcName, "auth") {
-			return true
-		}
-	}
-	return false
-}
-
 // capitalize returns the string with its first rune uppercased.
 func capitalize(s string) string {
 	if s == "" {
@@ -1,6 +1,7 @@
 package openapi
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -29,8 +30,8 @@ func TestGenerateSpecRoutes(t *testing.T) {
 		t.Fatalf("expected path '/foo/{id}' in spec.Paths")
 	}
 	ops := paths["/foo/{id}"]
-	op, ok := ops["get"]
-	if !ok {
+	op := ops.Get
+	if op == nil {
 		t.Fatalf("expected GET operation for '/foo/{id}'")
 	}
 
@@ -43,3 +44,211 @@ func TestGenerateSpecRoutes(t *testing.T) {
 		t.Errorf("unexpected path parameter: %+v", p)
 	}
 }
+
+// TestBuildResponses_Defaults checks method-aware default success responses.
+func TestBuildResponses_Defaults(t *testing.T) {
+	g := NewTestGenerator()
+	tests := []struct {
+		method  string
+		code    string
+		hasBody bool
+	}{
+		{http.MethodGet, "200", true},
+		{http.MethodPost, "201", true},
+		{http.MethodDelete, "204", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			responses := g.buildResponses(tc.method, nil)
+			resp, ok := responses[tc.code]
+			if !ok {
+				t.Fatalf("expected %s response, got %v", tc.code, responses)
+			}
+			AssertEqual(t, tc.hasBody, resp.Content != nil)
+		})
+	}
+}
+
+// TestBuildResponses_MultipleSuccess checks that every @Success line becomes a response.
+func TestBuildResponses_MultipleSuccess(t *testing.T) {
+	g := NewTestGenerator()
+	annotations := &Annotation{Successes: []SuccessResponse{
+		{StatusCode: 201, DataType: "string", Description: "Created"},
+		{StatusCode: 204},
+		{StatusCode: DefaultStatusCode, DataType: "string"},
+	}}
+	responses := g.buildResponses(http.MethodPost, annotations)
+
+	AssertEqual(t, "Created", responses["201"].Description)
+	AssertEqual(t, "No Content", responses["204"].Description)
+	if responses["204"].Content != nil {
+		t.Errorf("expected bodyless 204 response, got %+v", responses["204"].Content)
+	}
+	AssertEqual(t, "Default response", responses["default"].Description)
+}
+
+// TestBuildResponses_FailureTypes checks that @Failure types and @Produce drive error responses.
+func TestBuildResponses_FailureTypes(t *testing.T) {
+	g := NewTestGenerator()
+	annotations := &Annotation{
+		Produce: []string{"application/json"},
+		Failures: []ErrorResponse{
+			{StatusCode: 404, Type: "ProblemDetails", Description: "Not found"},
+			{StatusCode: 422, Type: "Contact", Description: "Validation failed"},
+		},
+	}
+	responses := g.buildResponses(http.MethodGet, annotations)
+
+	if _, ok := responses["404"].Content["application/problem+json"]; !ok {
+		t.Errorf("expected problem+json for ProblemDetails, got %+v", responses["404"].Content)
+	}
+	media, ok := responses["422"].Content["application/json"]
+	if !ok {
+		t.Fatalf("expected application/json for custom error type, got %+v", responses["422"].Content)
+	}
+	AssertEqual(t, "#/components/schemas/openapi.Contact", media.Schema.Ref)
+}
+
+// TestBuildResponses_Headers checks that @Header annotations attach to matching responses.
+func TestBuildResponses_Headers(t *testing.T) {
+	g := NewTestGenerator()
+	annotations := &Annotation{
+		Successes: []SuccessResponse{
+			{StatusCode: 200, DataType: "[]Contact"},
+			{StatusCode: 206, DataType: "[]Contact"},
+		},
+		Headers: []HeaderAnnotation{
+			{StatusCode: 200, Type: "integer", Name: "X-Total-Count", Description: "Total items"},
+			{StatusCode: 206, Type: "integer", Name: "X-Total-Count", Description: "Total items"},
+			{StatusCode: 206, Type: "MyEnum", Name: "X-Status"},
+			{StatusCode: 201, Type: "string", Name: "Location"},
+		},
+	}
+	responses := g.buildResponses(http.MethodGet, annotations)
+
+	total := responses["200"].Headers["X-Total-Count"]
+	AssertEqual(t, "Total items", total.Description)
+	AssertEqual(t, "integer", total.Schema.Type)
+	AssertEqual(t, "#/components/schemas/openapi.MyEnum", responses["206"].Headers["X-Status"].Schema.Ref)
+	if _, ok := responses["201"]; ok {
+		t.Error("expected no response to be created for an undocumented @Header status")
+	}
+}
+
+// TestApplyDeprecation checks that deprecated operations document sunset metadata on every response.
+func TestApplyDeprecation(t *testing.T) {
+	g := NewTestGenerator()
+	annotations := &Annotation{
+		Description: "Lists users.",
+		Deprecation: &DeprecationAnnotation{Sunset: "2026-12-31", Replacement: "use /v2/users"},
+	}
+	operation := g.buildOperation(annotations, "/users", http.MethodGet, nil)
+
+	AssertEqual(t, true, operation.Deprecated)
+	AssertEqual(t, "Lists users.\n\nDeprecated: Sunset on 2026-12-31. Use /v2/users.", operation.Description)
+	for code, response := range operation.Responses {
+		if _, ok := response.Headers["Deprecation"]; !ok {
+			t.Errorf("expected Deprecation header on %s response", code)
+		}
+		AssertEqual(t, "Thu, 31 Dec 2026 00:00:00 GMT", response.Headers["Sunset"].Example)
+	}
+
+	bare := g.buildOperation(&Annotation{Deprecation: &DeprecationAnnotation{}}, "/users", http.MethodGet, nil)
+	AssertEqual(t, true, bare.Deprecated)
+	AssertEqual(t, "", bare.Description)
+	if _, ok := bare.Responses["200"].Headers["Sunset"]; ok {
+		t.Error("expected no Sunset header without a sunset date")
+	}
+}
+
+// TestMediaTypes_AcceptProduce checks that @Accept and @Produce drive request and response content.
+func TestMediaTypes_AcceptProduce(t *testing.T) {
+	g := NewTestGenerator()
+	annotations := &Annotation{
+		Accept:     []string{"application/json", "text/xml"},
+		Produce:    []string{"text/xml"},
+		Parameters: []ParamAnnotation{{Name: "body", In: "body", Type: "string"}},
+		Successes:  []SuccessResponse{{StatusCode: 200, DataType: "string"}},
+	}
+
+	body := g.buildRequestBody(annotations)
+	if len(body.Content) != 2 {
+		t.Fatalf("expected 2 request media types, got %+v", body.Content)
+	}
+	if _, ok := body.Content["text/xml"]; !ok {
+		t.Errorf("expected text/xml request content, got %+v", body.Content)
+	}
+
+	responses := g.buildResponses(http.MethodPost, annotations)
+	if _, ok := responses["200"].Content["text/xml"]; !ok || len(responses["200"].Content) != 1 {
+		t.Errorf("expected only text/xml response content, got %+v", responses["200"].Content)
+	}
+}
+
+// publicTestHandler is reachable without credentials.
+// @Summary Public endpoint
+// @Security none
+func publicTestHandler(w http.ResponseWriter, r *http.Request) {}
+
+// scopedTestHandler requires OAuth2 scopes or an API key.
+// @Summary Scoped endpoint
+// @Security OAuth2[read:users] || ApiKeyAuth
+func scopedTestHandler(w http.ResponseWriter, r *http.Request) {}
+
+func jwtTestMiddleware(next http.Handler) http.Handler { return next }
+
+// TestGenerateSpec_SecurityAnnotations checks that @Security overrides middleware documentation.
+func TestGenerateSpec_SecurityAnnotations(t *testing.T) {
+	g := NewTestGenerator()
+	t.Cleanup(resetMiddlewareDocsForTesting)
+	DescribeMiddleware(jwtTestMiddleware, MiddlewareDoc{Security: []SecurityRequirement{{"BearerAuth": {}}}})
+
+	r := chi.NewRouter()
+	r.Use(jwtTestMiddleware)
+	r.Get("/public", publicTestHandler)
+	r.Get("/scoped", scopedTestHandler)
+	r.Get("/inferred", func(w http.ResponseWriter, r *http.Request) {})
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+
+	AssertDeepEqual(t, []SecurityRequirement{{}}, spec.Paths["/public"].Get.Security)
+	AssertDeepEqual(t,
+		[]SecurityRequirement{{"OAuth2": {"read:users"}}, {"ApiKeyAuth": {}}},
+		spec.Paths["/scoped"].Get.Security,
+	)
+	AssertDeepEqual(t, []SecurityRequirement{{"BearerAuth": {}}}, spec.Paths["/inferred"].Get.Security)
+}
+
+// TestGenerateSpec_ParallelGenerators runs generators sharing one TypeIndex in parallel; known
+// types added to one generator must not leak into the others. Run with -race.
+func TestGenerateSpec_ParallelGenerators(t *testing.T) {
+	idx := newTestIndex(t, "audience_test.go")
+	router := audienceTestRouter()
+	const audit = "openapi.AudienceTestAuditRecord"
+	shared := NewGeneratorWithCache(idx)
+
+	for i := 0; i < 8; i++ {
+		custom := i%2 == 0
+		audience := []string{"", "internal"}[i/2%2]
+		t.Run(fmt.Sprintf("generator%d", i), func(t *testing.T) {
+			t.Parallel()
+			g := NewGeneratorWithCache(idx)
+			if custom {
+				g.AddExternalKnownType(audit, &Schema{Type: "string", Format: "audit-record"})
+			}
+			cfg := Config{Title: "Test", Version: "1.0.0", Audience: audience}
+			spec := g.GenerateSpec(router, cfg)
+			shared.GenerateSpec(router, cfg)
+
+			response := spec.Paths["/accounts/audit"].Get.Responses["200"]
+			schema := response.Content["application/json"].Schema
+			_, component := spec.Components.Schemas[audit]
+			if custom {
+				AssertEqual(t, "audit-record", schema.Format)
+				AssertEqual(t, false, component)
+			} else {
+				AssertEqual(t, "#/components/schemas/"+audit, schema.Ref)
+				AssertEqual(t, true, component)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// Package openapi provides group-level annotations for chi Route, Group and Mount blocks.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Group creates an inline group like chi's Router.Group. The annotations on fn's declaration
+// (tags, security, failures, parameters and description) apply to every route registered in it.
+func Group(r chi.Router, fn func(r chi.Router)) chi.Router {
+	return r.Group(func(r chi.Router) {
+		r.Use(GroupAnnotations(fn))
+		fn(r)
+	})
+}
+
+// Route mounts a sub-router like chi's Router.Route, applying fn's annotations to its routes.
+func Route(r chi.Router, pattern string, fn func(r chi.Router)) chi.Router {
+	return r.Route(pattern, func(r chi.Router) {
+		r.Use(GroupAnnotations(fn))
+		fn(r)
+	})
+}
+
+// GroupAnnotations returns a pass-through middleware that applies the annotations on builder's
+// declaration to every route of the router it is used on. Use it for routers attached with Mount:
+//
+//	func AdminRouter() chi.Router {
+//		r := chi.NewRouter()
+//		r.Use(openapi.GroupAnnotations(AdminRouter))
+//		...
+//	}
+func GroupAnnotations(builder interface{}) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return &groupHandler{builder: builder, next: next}
+	}
+}
+
+// groupHandler carries a group builder through the middleware chain and otherwise delegates to next.
+type groupHandler struct {
+	builder interface{}
+	next    http.Handler
+}
+
+func (h *groupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.next.ServeHTTP(w, r)
+}
+
+// groupMarkerPC identifies GroupAnnotations middlewares; closures from one literal share their code pointer.
+var groupMarkerPC = reflect.ValueOf(GroupAnnotations(nil)).Pointer()
+
+// groupBuilders returns the group builders marked in a route's middleware chain, outermost first.
+// Only GroupAnnotations middlewares are invoked; other middlewares are never called.
+func groupBuilders(middlewares []func(http.Handler) http.Handler) []interface{} {
+	var builders []interface{}
+	for _, mw := range middlewares {
+		if mw == nil || reflect.ValueOf(mw).Pointer() != groupMarkerPC {
+			continue
+		}
+		if h, ok := mw(http.NotFoundHandler()).(*groupHandler); ok && h.builder != nil {
+			builders = append(builders, h.builder)
+		}
+	}
+	return builders
+}
+
+// groupAnnotations parses the annotations of the groups enclosing a route, outermost first.
+func (g *Generator) groupAnnotations(middlewares []func(http.Handler) http.Handler) []*Annotation {
+	var groups []*Annotation
+	for _, builder := range groupBuilders(middlewares) {
+		info := g.extractHandlerInfo(builder)
+		if info == nil || info.File == "" {
+			continue
+		}
+		annotations, err := g.parseHandlerAnnotations(info)
+		if err != nil {
+			g.log().Warn("[openapi] groupAnnotations: annotations parse error", "group", info.DeclName(), "error", err)
+		}
+		if annotations != nil {
+			groups = append(groups, annotations)
+		}
+	}
+	return groups
+}
+
+// mergeGroupAnnotations applies group annotations (outermost first) to a handler's annotations.
+// The handler's own tags and security win, otherwise the innermost group's are used; failures are
+// added for status codes the handler does not document, and parameters are prepended unless the
+// handler declares a parameter with the same name and location.
+func mergeGroupAnnotations(annotations *Annotation, groups []*Annotation) *Annotation {
+	if annotations == nil {
+		annotations = &Annotation{}
+	}
+
+	var groupParams []ParamAnnotation
+	for i := len(groups) - 1; i >= 0; i-- {
+		group := groups[i]
+		if group.Hidden {
+			annotations.Hidden = true
+		}
+		if len(annotations.Tags) == 0 {
+			annotations.Tags = group.Tags
+		}
+		if len(annotations.Audiences) == 0 {
+			annotations.Audiences = group.Audiences
+		}
+		if len(annotations.Security) == 0 {
+			annotations.Security = group.Security
+		}
+		for _, failure := range group.Failures {
+			if !hasFailure(annotations.Failures, failure.StatusCode) {
+				annotations.Failures = append(annotations.Failures, failure)
+			}
+		}
+		for _, param := range group.Parameters {
+			if !hasParam(annotations.Parameters, param) && !hasParam(groupParams, param) {
+				groupParams = append(groupParams, param)
+			}
+		}
+	}
+	annotations.Parameters = append(groupParams, annotations.Parameters...)
+	return annotations
+}
+
+// groupDescription returns the innermost group description, used as the path-level description.
+func groupDescription(groups []*Annotation) string {
+	for i := len(groups) - 1; i >= 0; i-- {
+		if groups[i].Description != "" {
+			return groups[i].Description
+		}
+	}
+	return ""
+}
+
+// hasFailure reports whether a failure response is declared for the status code.
+func hasFailure(failures []ErrorResponse, statusCode int) bool {
+	for _, failure := range failures {
+		if failure.StatusCode == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// hasParam reports whether params declares a parameter with the same name and location.
+// Struct parameters are compared by type.
+func hasParam(params []ParamAnnotation, param ParamAnnotation) bool {
+	for _, p := range params {
+		if p.In == param.In && p.Name == param.Name && (p.Name != "" || p.Type == param.Type) {
+			return true
+		}
+	}
+	return false
+}
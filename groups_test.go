@@ -0,0 +1,131 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// adminTestRoutes registers the admin endpoints.
+// @Description Administrative endpoints.
+// @Tags admin
+// @Security BearerAuth
+// @Failure 403 {object} ProblemDetails "Forbidden"
+// @Param X-Tenant header string true "Tenant identifier"
+func adminTestRoutes(r chi.Router) {
+	r.Get("/users", adminTestListUsers)
+	r.Get("/stats", adminTestStats)
+	Group(r, auditTestRoutes)
+}
+
+// auditTestRoutes registers the audit log endpoints.
+// @Tags audit
+// @Failure 404 {object} ProblemDetails "No audit log"
+func auditTestRoutes(r chi.Router) {
+	r.Get("/audit", adminTestStats)
+}
+
+// adminTestListUsers lists users for administrators.
+// @Summary List users (admin)
+// @Tags users
+// @Security none
+// @Param X-Tenant header string false "Optional tenant"
+func adminTestListUsers(w http.ResponseWriter, r *http.Request) {}
+
+func adminTestStats(w http.ResponseWriter, r *http.Request) {}
+
+// internalTestRoutes registers operator-only endpoints.
+// @Hidden
+func internalTestRoutes(r chi.Router) {
+	r.Get("/flags", adminTestStats)
+}
+
+// billingTestRouter builds a router that is attached with Mount.
+// @Tags billing
+func billingTestRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Use(GroupAnnotations(billingTestRouter))
+	r.Get("/invoices", adminTestStats)
+	return r
+}
+
+func TestGenerateSpec_GroupAnnotations(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "groups_test.go"))
+
+	r := chi.NewRouter()
+	Route(r, "/admin", adminTestRoutes)
+	r.Mount("/billing", billingTestRouter())
+	Route(r, "/internal", internalTestRoutes)
+	r.Get("/health", adminTestStats)
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+
+	// Undocumented handlers inherit everything from the group
+	stats := spec.Paths["/admin/stats"].Get
+	AssertDeepEqual(t, []string{"admin"}, stats.Tags)
+	AssertDeepEqual(t, []SecurityRequirement{{"BearerAuth": {}}}, stats.Security)
+	AssertEqual(t, "Forbidden", stats.Responses["403"].Description)
+	AssertEqual(t, "X-Tenant", stats.Parameters[0].Name)
+	AssertEqual(t, true, stats.Parameters[0].Required)
+
+	// Handler annotations win over the group's
+	users := spec.Paths["/admin/users"].Get
+	AssertDeepEqual(t, []string{"users"}, users.Tags)
+	AssertDeepEqual(t, []SecurityRequirement{{}}, users.Security)
+	AssertEqual(t, 1, len(users.Parameters))
+	AssertEqual(t, false, users.Parameters[0].Required)
+	AssertEqual(t, "Forbidden", users.Responses["403"].Description)
+
+	// Nested groups: the innermost tags win, failures and parameters accumulate
+	audit := spec.Paths["/admin/audit"].Get
+	AssertDeepEqual(t, []string{"audit"}, audit.Tags)
+	AssertEqual(t, "No audit log", audit.Responses["404"].Description)
+	AssertEqual(t, "Forbidden", audit.Responses["403"].Description)
+	AssertEqual(t, "X-Tenant", audit.Parameters[0].Name)
+
+	AssertDeepEqual(t, []string{"billing"}, spec.Paths["/billing/invoices"].Get.Tags)
+	AssertDeepEqual(t, []string{"health"}, spec.Paths["/health"].Get.Tags)
+	if _, ok := spec.Paths["/internal/flags"]; ok {
+		t.Error("expected routes in a @Hidden group to be omitted")
+	}
+
+	AssertEqual(t, "Administrative endpoints.", spec.Paths["/admin/stats"].Description)
+	if spec.Paths["/health"].Description != "" {
+		t.Error("expected no path description outside groups")
+	}
+}
+
+func TestSpecJSON_PathItemDescription(t *testing.T) {
+	spec := Spec{
+		OpenAPI: "3.1.0",
+		Info:    Info{Title: "Test", Version: "1.0.0"},
+		Paths: map[string]*PathItem{
+			"/admin": {Description: "Administrative endpoints.", Get: &Operation{OperationID: "admin"}},
+		},
+	}
+
+	data, err := json.Marshal(spec)
+	AssertNoError(t, err)
+	var decoded Spec
+	AssertNoError(t, json.Unmarshal(data, &decoded))
+	AssertDeepEqual(t, spec.Paths["/admin"], decoded.Paths["/admin"])
+
+	var paths struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	AssertNoError(t, json.Unmarshal(data, &paths))
+	AssertEqual(t, "Administrative endpoints.", paths.Paths["/admin"]["description"])
+	AssertEqual(t, 2, len(paths.Paths["/admin"]))
+}
+
+func TestGroupBuilders_IgnoresOtherMiddleware(t *testing.T) {
+	called := false
+	other := func(next http.Handler) http.Handler {
+		called = true
+		return next
+	}
+	builders := groupBuilders([]func(http.Handler) http.Handler{other, GroupAnnotations(adminTestRoutes)})
+	AssertEqual(t, 1, len(builders))
+	AssertEqual(t, false, called)
+}
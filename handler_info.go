@@ -0,0 +1,294 @@
+package openapi
+
+import (
+	"go/ast"
+	"go/token"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// HandlerInfo identifies the Go declaration behind a route handler.
+// For struct methods, Receiver holds the receiver's base type name (without pointer or type parameters).
+// Closure is set when the handler is a function literal, in which case FunctionName and Receiver
+// name the enclosing declaration (e.g. the handler factory that returned it) and Line is the line
+// of the literal.
+type HandlerInfo struct {
+	File         string
+	Line         int
+	FunctionName string
+	Package      string
+	Receiver     string
+	Closure      bool
+}
+
+// HandlerUnwrapper is implemented by handler adapters that wrap another handler function,
+// e.g. an adapter turning func(w, r) error into an http.Handler. Annotations are read from
+// the declaration of the returned value instead of the adapter's ServeHTTP method.
+type HandlerUnwrapper interface {
+	UnwrapHandler() interface{}
+}
+
+// maxUnwrapDepth bounds HandlerUnwrapper chains to guard against adapters that return themselves.
+const maxUnwrapDepth = 16
+
+// DeclName returns the name used to look up the handler's declaration,
+// e.g. "ListUsers" for functions and "UserHandler.List" for methods.
+func (hi *HandlerInfo) DeclName() string {
+	if hi.Receiver != "" {
+		return hi.Receiver + "." + hi.FunctionName
+	}
+	return hi.FunctionName
+}
+
+// extractHandlerInfo gets information about a handler function.
+// Adapters are unwrapped first; method values (e.g. h.List) are resolved to the receiver type and
+// the file declaring the method, and closures to their enclosing declaration.
+func (g *Generator) extractHandlerInfo(handler interface{}) *HandlerInfo {
+	g.log().Debug("[openapi] extractHandlerInfo: called")
+	fn := resolveHandlerFunc(handler)
+	if fn == nil {
+		return nil
+	}
+
+	pc := reflect.ValueOf(fn).Pointer()
+	funcInfo := runtime.FuncForPC(pc)
+	if funcInfo == nil {
+		return nil
+	}
+
+	file, line := funcInfo.FileLine(pc)
+	pkgPath, receiver, name, closure := parseRuntimeFuncName(funcInfo.Name())
+	info := &HandlerInfo{
+		File:         file,
+		Line:         line,
+		FunctionName: name,
+		Package:      pkgPath,
+		Receiver:     receiver,
+		Closure:      closure,
+	}
+
+	// Method value wrappers are compiler generated, so locate the method declaration via the TypeIndex
+	if receiver != "" && (file == "" || file == "<autogenerated>") {
+		if resolved := g.schemaGen.typeIndex.LookupFuncFile(pkgPath, receiver, name); resolved != "" {
+			info.File = resolved
+		}
+	}
+
+	g.log().Debug(
+		"[openapi] extractHandlerInfo: found handler info",
+		"file", info.File,
+		"function", info.FunctionName,
+		"receiver", info.Receiver,
+		"closure", info.Closure,
+	)
+	return info
+}
+
+// parseHandlerAnnotations reads the annotations for a resolved handler.
+// Closures only inherit the enclosing declaration's doc comment when that declaration is a
+// handler factory returning the closure, so inline closures in route setup functions (even ones
+// returning the router as an http.Handler) and closures returned by function adapters (which
+// describe the wrapped handler, not themselves) stay undocumented.
+func (g *Generator) parseHandlerAnnotations(info *HandlerInfo) (*Annotation, error) {
+	astFile, fset, err := g.loadAnnotationFile(info.File)
+	if astFile == nil {
+		return nil, err
+	}
+	funcDecl := findFuncDecl(astFile, info.DeclName(), g.log())
+	if funcDecl == nil {
+		g.log().Debug("[openapi] parseHandlerAnnotations: no comment found", "function", info.DeclName())
+		return nil, nil
+	}
+	if info.Closure && (!isHandlerFactory(funcDecl) || !returnsFuncLitAt(funcDecl, fset, info.Line)) {
+		g.log().Debug("[openapi] parseHandlerAnnotations: closure has no documented factory", "function", info.DeclName())
+		return nil, nil
+	}
+	return annotationsFromDecl(funcDecl, info.File, g.log()), nil
+}
+
+// resolveHandlerFunc returns the function value whose declaration documents a handler.
+// HandlerUnwrapper chains are followed, func-typed adapters are used as-is, and other
+// http.Handler implementations resolve to their ServeHTTP method.
+func resolveHandlerFunc(handler interface{}) interface{} {
+	for i := 0; i < maxUnwrapDepth; i++ {
+		unwrapper, ok := handler.(HandlerUnwrapper)
+		if !ok {
+			break
+		}
+		inner := unwrapper.UnwrapHandler()
+		if inner == nil {
+			break
+		}
+		handler = inner
+	}
+
+	if handler == nil {
+		return nil
+	}
+	if reflect.ValueOf(handler).Kind() == reflect.Func {
+		return handler
+	}
+	if h, ok := handler.(http.Handler); ok {
+		return h.ServeHTTP
+	}
+	return nil
+}
+
+// isHandlerFactory reports whether a declaration is a handler factory: it returns a handler but
+// takes none. Adapters such as func Wrap(h http.HandlerFunc) http.HandlerFunc return a closure
+// around another handler, so their doc comment does not describe the route.
+func isHandlerFactory(fn *ast.FuncDecl) bool {
+	if fn.Type.Results == nil || len(fn.Type.Results.List) == 0 || !isHandlerType(fn.Type.Results.List[0].Type) {
+		return false
+	}
+	for _, param := range fn.Type.Params.List {
+		if isHandlerType(param.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+// returnsFuncLitAt reports whether a return statement of fn returns a function literal starting
+// on the given line, either directly or converted (e.g. return http.HandlerFunc(func(...) {...})).
+// Return statements of nested function literals are not considered.
+func returnsFuncLitAt(fn *ast.FuncDecl, fset *token.FileSet, line int) bool {
+	if fn.Body == nil || fset == nil || line == 0 {
+		return false
+	}
+	found := false
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			for _, result := range n.Results {
+				if lit := returnedFuncLit(result); lit != nil && fset.Position(lit.Pos()).Line == line {
+					found = true
+				}
+			}
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// returnedFuncLit unwraps parentheses and single-argument conversions around a function literal.
+func returnedFuncLit(expr ast.Expr) *ast.FuncLit {
+	for {
+		switch e := expr.(type) {
+		case *ast.FuncLit:
+			return e
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.CallExpr:
+			if len(e.Args) != 1 {
+				return nil
+			}
+			expr = e.Args[0]
+		default:
+			return nil
+		}
+	}
+}
+
+// isHandlerType reports whether a type expression is a function type or a type named
+// Handler/HandlerFunc (http.HandlerFunc, AppHandler, ...).
+func isHandlerType(expr ast.Expr) bool {
+	var typeName string
+	switch t := expr.(type) {
+	case *ast.FuncType:
+		return true
+	case *ast.StarExpr:
+		return isHandlerType(t.X)
+	case *ast.Ident:
+		typeName = t.Name
+	case *ast.SelectorExpr:
+		typeName = t.Sel.Name
+	}
+	return strings.HasSuffix(typeName, "Handler") || strings.HasSuffix(typeName, "HandlerFunc")
+}
+
+// parseRuntimeFuncName splits a runtime function name into import path, receiver type and function name.
+// Closure suffixes (".func1", ".func1.2") are stripped and reported, leaving the enclosing declaration.
+// e.g. "github.com/acme/api.(*UserHandler).List-fm" -> ("github.com/acme/api", "UserHandler", "List", false).
+func parseRuntimeFuncName(fullName string) (pkgPath, receiver, name string, closure bool) {
+	// The package path ends at the first dot after the last slash
+	pathEnd := strings.LastIndex(fullName, "/") + 1
+	dot := strings.Index(fullName[pathEnd:], ".")
+	if dot == -1 {
+		return "", "", fullName, false
+	}
+	pkgPath = fullName[:pathEnd+dot]
+	symbol := strings.TrimSuffix(fullName[pathEnd+dot+1:], "-fm")
+	// Generic instantiations are always reported as [...]
+	symbol = strings.ReplaceAll(symbol, "[...]", "")
+
+	// Pointer receiver: (*Type).Method
+	if strings.HasPrefix(symbol, "(*") {
+		if end := strings.Index(symbol, ")."); end != -1 {
+			receiver = symbol[2:end]
+			symbol = symbol[end+2:]
+		}
+	}
+
+	parts := strings.Split(symbol, ".")
+	for len(parts) > 1 && isClosureSegment(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+		closure = true
+	}
+
+	// Value receiver: Type.Method
+	if receiver == "" && len(parts) == 2 {
+		receiver = parts[0]
+		parts = parts[1:]
+	}
+
+	return pkgPath, receiver, strings.Join(parts, "."), closure
+}
+
+// isClosureSegment reports whether a runtime name segment denotes a function literal ("func1" or "2").
+func isClosureSegment(segment string) bool {
+	digits := strings.TrimPrefix(segment, "func")
+	if digits == "" {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// funcDeclName returns the lookup name for a function declaration: "Name" or "Receiver.Name".
+func funcDeclName(fn *ast.FuncDecl) string {
+	if recv := receiverTypeName(fn); recv != "" {
+		return recv + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// receiverTypeName returns the base type name of a method receiver, or "" for plain functions.
+func receiverTypeName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		expr = t.X
+	case *ast.IndexListExpr:
+		expr = t.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
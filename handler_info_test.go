@@ -0,0 +1,208 @@
+package openapi
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// --- Test handlers bound to struct receivers ---
+type userTestHandler struct{}
+
+type orderTestHandler struct{}
+
+// List returns users.
+// @Summary List users
+// @Tags users
+func (h *userTestHandler) List(w http.ResponseWriter, r *http.Request) {}
+
+// Get returns a single user.
+// @Summary Get user
+func (h userTestHandler) Get(w http.ResponseWriter, r *http.Request) {}
+
+// List returns orders.
+// @Summary List orders
+// @Tags orders
+func (h *orderTestHandler) List(w http.ResponseWriter, r *http.Request) {}
+
+// ListTestOrders builds the order listing handler.
+// @Summary List orders via factory
+// @Tags orders
+func ListTestOrders(prefix string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) { _ = prefix }
+}
+
+// wrapTestLogging is a function adapter; routes it wraps must not inherit this comment.
+// @Summary Logging adapter
+func wrapTestLogging(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) { next(w, r) }
+}
+
+// registerTestRoutes registers inline handlers, which must not inherit this comment.
+// @Summary Route setup
+func registerTestRoutes(r chi.Router) {
+	r.Get("/inline", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+// routesTestPets mounts the pet API; its inline handlers must not inherit this comment.
+// @Summary Mounts the pet API
+// @Tags petstore
+func routesTestPets() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/pets", func(w http.ResponseWriter, r *http.Request) {})
+	r.Post("/pets", func(w http.ResponseWriter, r *http.Request) {})
+	return r
+}
+
+// ListTestPets builds the pet listing handler with a conversion around the returned literal.
+// @Summary List pets via factory
+func ListTestPets() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+}
+
+// errTestHandler adapts handlers that return errors.
+type errTestHandler func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP runs the wrapped handler.
+// @Summary Adapter
+func (fn errTestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) { _ = fn(w, r) }
+
+// wrapTestHandler is a struct adapter exposing the wrapped handler.
+type wrapTestHandler struct {
+	next func(w http.ResponseWriter, r *http.Request) error
+}
+
+// ServeHTTP runs the wrapped handler.
+// @Summary Struct adapter
+func (h wrapTestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) { _ = h.next(w, r) }
+
+// UnwrapHandler returns the wrapped handler.
+func (h wrapTestHandler) UnwrapHandler() interface{} { return h.next }
+
+// DeleteTestOrder deletes an order.
+// @Summary Delete order
+func DeleteTestOrder(w http.ResponseWriter, r *http.Request) error { return nil }
+
+// GetTestOrder returns an order by ID. Archived orders are included.
+// @Tags orders
+func GetTestOrder(w http.ResponseWriter, r *http.Request) {}
+
+// newTestIndex builds a TypeIndex that also covers the given test files.
+func newTestIndex(t *testing.T, files ...string) *TypeIndex {
+	t.Helper()
+	ResetGlobals()
+	idx := BuildTypeIndex()
+	for _, f := range files {
+		AssertNoError(t, idx.indexFile(f, slog.Default()))
+	}
+	return idx
+}
+
+func TestParseRuntimeFuncName(t *testing.T) {
+	tests := []struct {
+		in       string
+		pkg      string
+		receiver string
+		name     string
+		closure  bool
+	}{
+		{"github.com/acme/api.ListUsers", "github.com/acme/api", "", "ListUsers", false},
+		{"github.com/acme/api.(*UserHandler).List-fm", "github.com/acme/api", "UserHandler", "List", false},
+		{"github.com/acme/api.UserHandler.Get-fm", "github.com/acme/api", "UserHandler", "Get", false},
+		{"github.com/acme/api.(*Repo[...]).List-fm", "github.com/acme/api", "Repo", "List", false},
+		{"github.com/acme/api.ListUsers.func1", "github.com/acme/api", "", "ListUsers", true},
+		{"github.com/acme/api.Nested.func1.func2", "github.com/acme/api", "", "Nested", true},
+		{"github.com/acme/api.Nested.func1.1", "github.com/acme/api", "", "Nested", true},
+		{"github.com/acme/api.Gen[...].func1", "github.com/acme/api", "", "Gen", true},
+		{"github.com/acme/api.(*UserHandler).Routes.func1", "github.com/acme/api", "UserHandler", "Routes", true},
+		{"main.Health", "main", "", "Health", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			pkg, receiver, name, closure := parseRuntimeFuncName(tc.in)
+			AssertEqual(t, tc.pkg, pkg)
+			AssertEqual(t, tc.receiver, receiver)
+			AssertEqual(t, tc.name, name)
+			AssertEqual(t, tc.closure, closure)
+		})
+	}
+}
+
+func TestExtractHandlerInfo_Method(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "handler_info_test.go"))
+	h := &userTestHandler{}
+
+	info := g.extractHandlerInfo(http.HandlerFunc(h.List))
+	if info == nil {
+		t.Fatal("expected handler info for method value")
+	}
+	AssertEqual(t, "userTestHandler", info.Receiver)
+	AssertEqual(t, "userTestHandler.List", info.DeclName())
+	AssertEqual(t, "handler_info_test.go", info.File)
+}
+
+func TestGenerateSpec_MethodHandlers(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "handler_info_test.go"))
+	users, orders := &userTestHandler{}, &orderTestHandler{}
+
+	r := chi.NewRouter()
+	r.Get("/users", users.List)
+	r.Get("/users/{id}", userTestHandler{}.Get)
+	r.Get("/orders", orders.List)
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+
+	AssertEqual(t, "List users", spec.Paths["/users"].Get.Summary)
+	AssertEqual(t, "Get user", spec.Paths["/users/{id}"].Get.Summary)
+	AssertEqual(t, "List orders", spec.Paths["/orders"].Get.Summary)
+	AssertDeepEqual(t, []string{"orders"}, spec.Paths["/orders"].Get.Tags)
+}
+
+func TestGenerateSpec_DocCommentFallback(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "handler_info_test.go"))
+	r := chi.NewRouter()
+	r.Get("/orders/{id}", GetTestOrder)
+
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+	AssertEqual(t, "", spec.Paths["/orders/{id}"].Get.Summary)
+
+	spec = g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0", DocCommentFallback: true})
+	AssertEqual(t, "Returns an order by ID", spec.Paths["/orders/{id}"].Get.Summary)
+	AssertEqual(t, "Archived orders are included.", spec.Paths["/orders/{id}"].Get.Description)
+}
+
+func TestGenerateSpec_ClosuresAndAdapters(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "handler_info_test.go"))
+
+	r := chi.NewRouter()
+	r.Get("/orders", ListTestOrders("v1"))
+	r.Method(http.MethodDelete, "/orders/{id}", errTestHandler(DeleteTestOrder))
+	r.Method(http.MethodPut, "/orders/{id}", wrapTestHandler{next: DeleteTestOrder})
+	r.Get("/orders/{id}/wrapped", wrapTestLogging(ListTestOrders("v2")))
+	registerTestRoutes(r)
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+
+	AssertEqual(t, "List orders via factory", spec.Paths["/orders"].Get.Summary)
+	AssertEqual(t, "Delete order", spec.Paths["/orders/{id}"].Delete.Summary)
+	AssertEqual(t, "Delete order", spec.Paths["/orders/{id}"].Put.Summary)
+	AssertEqual(t, "", spec.Paths["/inline"].Get.Summary)
+	AssertEqual(t, "", spec.Paths["/orders/{id}/wrapped"].Get.Summary)
+}
+
+func TestGenerateSpec_RouteBuilderClosures(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "handler_info_test.go"))
+
+	r := chi.NewRouter()
+	r.Mount("/api", routesTestPets())
+	r.Method(http.MethodGet, "/pets/all", ListTestPets())
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+
+	for _, operation := range []*Operation{spec.Paths["/api/pets"].Get, spec.Paths["/api/pets"].Post} {
+		AssertEqual(t, "", operation.Summary)
+		AssertDeepEqual(t, []string{"pets"}, operation.Tags)
+	}
+	AssertEqual(t, "getApiPets", spec.Paths["/api/pets"].Get.OperationID)
+	AssertEqual(t, "postApiPets", spec.Paths["/api/pets"].Post.OperationID)
+	AssertEqual(t, "List pets via factory", spec.Paths["/pets/all"].Get.Summary)
+}
@@ -5,19 +5,18 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"sync"
+	"weak"
 
 	"github.com/go-chi/chi/v5"
 )
 
 // CachedHandler returns an HTTP handler that serves the OpenAPI specification.
 // The specification is cached and only regenerated when refresh=true is passed
-// as a query parameter or when the cache is invalidated.
+// as a query parameter or when the cache is invalidated. Every call creates its own
+// cache; use NewSpecCache to invalidate or warm up a single spec.
 func CachedHandler(router chi.Router, cfg Config) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		refresh := r.URL.Query().Get("refresh") == "true"
-		spec := fetchSpec(router, cfg, refresh)
-		writeSpec(w, spec)
-	}
+	return registerHandlerCache(NewSpecCache(router, cfg)).Handler()
 }
 
 // writeSpec writes the OpenAPI specification as JSON to the response writer.
@@ -30,27 +29,69 @@ func writeSpec(w http.ResponseWriter, spec Spec) {
 	}
 }
 
-// InvalidateCache invalidates the cached OpenAPI specification.
-// The next request will trigger regeneration of the specification.
+// handlerCaches holds weak references to the caches created by CachedHandler and
+// GenerateFileHandler, so that InvalidateCache can reach them without keeping caches alive
+// after their handlers are gone.
+var handlerCaches struct {
+	sync.Mutex
+	caches []weak.Pointer[SpecCache]
+}
+
+// registerHandlerCache records a cache for InvalidateCache and returns it.
+func registerHandlerCache(cache *SpecCache) *SpecCache {
+	handlerCaches.Lock()
+	defer handlerCaches.Unlock()
+	handlerCaches.caches = append(liveHandlerCaches(), weak.Make(cache))
+	return cache
+}
+
+// liveHandlerCaches drops the references to collected caches and returns the rest.
+// The caller must hold handlerCaches.
+func liveHandlerCaches() []weak.Pointer[SpecCache] {
+	live := handlerCaches.caches[:0]
+	for _, ref := range handlerCaches.caches {
+		if ref.Value() != nil {
+			live = append(live, ref)
+		}
+	}
+	clear(handlerCaches.caches[len(live):])
+	return live
+}
+
+// InvalidateCache invalidates the specifications cached by every CachedHandler and GenerateFileHandler
+// that is still in use. The next request will trigger regeneration of the specification.
+//
+// Deprecated: Use SpecCache.InvalidateHandler to invalidate a single spec.
 func InvalidateCache(w http.ResponseWriter, _ *http.Request) {
-	cacheMutex.Lock()
-	cacheValid = false
-	cacheMutex.Unlock()
-	slog.Debug("[openapi] InvalidateCache: OpenAPI cache invalidated")
+	handlerCaches.Lock()
+	handlerCaches.caches = liveHandlerCaches()
+	refs := append([]weak.Pointer[SpecCache](nil), handlerCaches.caches...)
+	handlerCaches.Unlock()
+	for _, ref := range refs {
+		if cache := ref.Value(); cache != nil {
+			cache.Invalidate()
+		}
+	}
+	slog.Debug("[openapi] InvalidateCache: OpenAPI caches invalidated", "count", len(refs))
 	w.WriteHeader(http.StatusOK)
 }
 
 // GenerateOpenAPISpecFile generates the OpenAPI spec and writes it to the given file path.
+//
+// The refresh parameter is deprecated and ignored: there is no cache to refresh, the spec is
+// always generated from the router. Use SpecCache.WriteFile to write a cached spec.
 func GenerateOpenAPISpecFile(router chi.Router, cfg Config, filePath string, refresh bool) error {
 	slog.Debug("[openapi] GenerateOpenAPISpecFile: generating OpenAPI spec", "filePath", filePath)
+	return NewSpecCache(router, cfg).WriteFile(filePath)
+}
 
-	spec := fetchSpec(router, cfg, refresh)
-
-	slog.Debug("[openapi] GenerateOpenAPISpecFile: writing OpenAPI spec to file", "version", spec.Info.Version)
+// writeSpecFile writes the OpenAPI spec to filePath as indented JSON.
+func writeSpecFile(spec Spec, filePath string) error {
+	slog.Debug("[openapi] writeSpecFile: writing OpenAPI spec to file", "version", spec.Info.Version)
 
 	file, err := os.Create(filePath)
 	if err != nil {
-		slog.Debug("[openapi] GenerateOpenAPISpecFile: failed to create file", "err", err)
+		slog.Debug("[openapi] writeSpecFile: failed to create file", "err", err)
 		return err
 	}
 	defer file.Close()
@@ -58,21 +99,24 @@ func GenerateOpenAPISpecFile(router chi.Router, cfg Config, filePath string, ref
 	enc := json.NewEncoder(file)
 	enc.SetIndent("", "  ")
 	if err = enc.Encode(spec); err != nil {
-		slog.Debug("[openapi] GenerateOpenAPISpecFile: failed to write file", "err", err)
+		slog.Debug("[openapi] writeSpecFile: failed to write file", "err", err)
 		return err
 	}
 
-	slog.Debug("[openapi] GenerateOpenAPISpecFile: openapi.json written successfully")
+	slog.Debug("[openapi] writeSpecFile: spec written successfully", "filePath", filePath)
 	return nil
 }
 
 // GenerateFileHandler is an HTTP handler that generates the OpenAPI spec file and returns a status message.
 func GenerateFileHandler(router chi.Router, cfg Config) http.HandlerFunc {
+	cache := registerHandlerCache(NewSpecCache(router, cfg))
 	return func(w http.ResponseWriter, r *http.Request) {
-		refresh := r.URL.Query().Get("refresh") == "true"
-
-		err := GenerateOpenAPISpecFile(router, cfg, "openapi.json", refresh)
+		spec, err := cache.load(r.URL.Query().Get("refresh") == "true")
 		if err != nil {
+			http.Error(w, "Failed to generate OpenAPI spec", http.StatusInternalServerError)
+			return
+		}
+		if err := writeSpecFile(spec, "openapi.json"); err != nil {
 			http.Error(w, "Failed to write file", http.StatusInternalServerError)
 			return
 		}
@@ -83,30 +127,3 @@ func GenerateFileHandler(router chi.Router, cfg Config) http.HandlerFunc {
 		_, _ = w.Write([]byte(`{"message":"openapi.json created"}`))
 	}
 }
-
-// getCachedSpec retrieves the current cached spec and whether it is still valid.
-func getCachedSpec(refresh bool) (Spec, bool) {
-	cacheMutex.RLock()
-	defer cacheMutex.RUnlock()
-	return specCache, cacheValid && !refresh
-}
-
-// setCachedSpec updates the cache with a new spec and marks it valid.
-func setCachedSpec(s Spec) {
-	cacheMutex.Lock()
-	defer cacheMutex.Unlock()
-	specCache = s
-	cacheValid = true
-}
-
-// fetchSpec handles cache: returns cached spec or regenerates if needed.
-func fetchSpec(router chi.Router, cfg Config, refresh bool) Spec {
-	ensureTypeIndex()
-	if spec, ok := getCachedSpec(refresh); ok {
-		return spec
-	}
-	gen := NewGeneratorWithCache(typeIndex)
-	newSpec := gen.GenerateSpec(router, cfg)
-	setCachedSpec(newSpec)
-	return newSpec
-}
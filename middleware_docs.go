@@ -0,0 +1,236 @@
+// Package openapi provides documentation contributed by route middleware.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// MiddlewareDoc describes what a middleware adds to every operation it wraps.
+type MiddlewareDoc struct {
+	// Security requirements enforced by the middleware. Requirements of several middlewares on
+	// one route are combined (all must be satisfied); alternatives within one middleware are kept.
+	Security []SecurityRequirement
+	// Parameters the middleware reads, e.g. an X-Tenant-ID header.
+	Parameters []Parameter
+	// Responses the middleware may produce, keyed by status code (e.g. "401", "429").
+	Responses map[string]Response
+	// Extensions added to the operation. Keys must start with "x-"; others are ignored.
+	Extensions map[string]interface{}
+}
+
+// middlewareEntry is a middleware documented with WithMiddlewareDoc.
+type middlewareEntry struct {
+	mw  func(http.Handler) http.Handler
+	doc MiddlewareDoc
+}
+
+// middlewareRegistry maps middleware functions to their documentation.
+type middlewareRegistry struct {
+	mutex   sync.RWMutex
+	entries map[uintptr]MiddlewareDoc
+}
+
+// globalMiddlewareDocs holds the documentation registered with DescribeMiddleware. It is shared
+// by every Generator in the process.
+var globalMiddlewareDocs middlewareRegistry
+
+// describe registers the documentation of mw, replacing any earlier registration.
+func (r *middlewareRegistry) describe(mw func(http.Handler) http.Handler, doc MiddlewareDoc) {
+	if mw == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.entries == nil {
+		r.entries = make(map[uintptr]MiddlewareDoc)
+	}
+	r.entries[reflect.ValueOf(mw).Pointer()] = doc
+}
+
+// lookup returns the registered documentation for a middleware.
+func (r *middlewareRegistry) lookup(mw func(http.Handler) http.Handler) (MiddlewareDoc, bool) {
+	if mw == nil {
+		return MiddlewareDoc{}, false
+	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	doc, ok := r.entries[reflect.ValueOf(mw).Pointer()]
+	return doc, ok
+}
+
+// DescribeMiddleware registers the documentation contributed by a middleware for every Generator
+// in the process. Use WithMiddlewareDoc or Generator.DescribeMiddleware to describe a middleware
+// for one generator only; those registrations take precedence over this one.
+//
+// Middlewares are identified by their function: method values of one method, such as
+// auth.Require, and closures returned by one constructor, such as RequireRole("admin") and
+// RequireRole("user"), share one description. Wrap a middleware with Documented to describe
+// that instance alone.
+func DescribeMiddleware(mw func(http.Handler) http.Handler, doc MiddlewareDoc) {
+	globalMiddlewareDocs.describe(mw, doc)
+}
+
+// resetMiddlewareDocsForTesting clears all middleware documentation registered with
+// DescribeMiddleware.
+func resetMiddlewareDocsForTesting() {
+	globalMiddlewareDocs.mutex.Lock()
+	globalMiddlewareDocs.entries = nil
+	globalMiddlewareDocs.mutex.Unlock()
+}
+
+// Documented returns mw carrying its own documentation, which takes precedence over registered
+// documentation. Use it for middleware instances that need different descriptions:
+//
+//	r.With(openapi.Documented(RequireScope("admin"), adminDoc)).Delete("/users/{id}", DeleteUser)
+//
+// The result behaves exactly like mw.
+func Documented(mw func(http.Handler) http.Handler, doc MiddlewareDoc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if next == middlewareDocProbe {
+			return &middlewareDocHandler{doc: doc}
+		}
+		return mw(next)
+	}
+}
+
+// middlewareDocHandler carries the documentation of a Documented middleware out of it.
+type middlewareDocHandler struct {
+	doc MiddlewareDoc
+}
+
+func (h *middlewareDocHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {}
+
+// middlewareDocProbe is passed to Documented middlewares to read their documentation without
+// calling the wrapped middleware.
+var middlewareDocProbe http.Handler = &middlewareDocHandler{}
+
+// documentedMarkerPC identifies Documented middlewares; closures from one literal share their code pointer.
+var documentedMarkerPC = reflect.ValueOf(Documented(nil, MiddlewareDoc{})).Pointer()
+
+// documentedMiddlewareDoc returns the documentation carried by a Documented middleware.
+func documentedMiddlewareDoc(mw func(http.Handler) http.Handler) (MiddlewareDoc, bool) {
+	if mw == nil || reflect.ValueOf(mw).Pointer() != documentedMarkerPC {
+		return MiddlewareDoc{}, false
+	}
+	h, ok := mw(middlewareDocProbe).(*middlewareDocHandler)
+	if !ok {
+		return MiddlewareDoc{}, false
+	}
+	return h.doc, true
+}
+
+// DescribeMiddleware registers the documentation contributed by a middleware for this generator
+// only. It takes precedence over the package-level DescribeMiddleware.
+func (g *Generator) DescribeMiddleware(mw func(http.Handler) http.Handler, doc MiddlewareDoc) {
+	g.middlewareDocs.describe(mw, doc)
+}
+
+// lookupMiddlewareDoc returns the documentation of a middleware: its own if it is Documented,
+// otherwise the generator's, falling back to the package-level registry.
+func (g *Generator) lookupMiddlewareDoc(mw func(http.Handler) http.Handler) (MiddlewareDoc, bool) {
+	if doc, ok := documentedMiddlewareDoc(mw); ok {
+		return doc, true
+	}
+	if doc, ok := g.middlewareDocs.lookup(mw); ok {
+		return doc, true
+	}
+	return globalMiddlewareDocs.lookup(mw)
+}
+
+// applyMiddlewareDocs merges the documentation of a route's middlewares into its operation.
+// Annotations take precedence: @Security replaces middleware security, and responses declared
+// with @Success/@Failure or parameters already on the operation are kept. Middleware responses
+// do replace the generator's standard error responses.
+func (g *Generator) applyMiddlewareDocs(operation *Operation, middlewares []func(http.Handler) http.Handler, annotations *Annotation) {
+	var security []SecurityRequirement
+	for _, mw := range middlewares {
+		doc, ok := g.lookupMiddlewareDoc(mw)
+		if !ok {
+			continue
+		}
+		g.log().Debug("[openapi] applyMiddlewareDocs: applying middleware documentation", "operationId", operation.OperationID)
+
+		if len(doc.Security) > 0 {
+			security = combineSecurity(security, doc.Security)
+		}
+		for _, param := range doc.Parameters {
+			if !hasParameter(operation.Parameters, param.Name, param.In) {
+				operation.Parameters = append(operation.Parameters, param)
+			}
+		}
+		for code, response := range doc.Responses {
+			if !annotatesResponse(annotations, code) {
+				operation.Responses[code] = response
+			}
+		}
+		for key, value := range doc.Extensions {
+			// Other keys would replace operation fields such as responses when marshalled
+			if !strings.HasPrefix(key, "x-") {
+				g.log().Warn("[openapi] applyMiddlewareDocs: ignoring extension without x- prefix", "key", key, "operationId", operation.OperationID)
+				continue
+			}
+			if operation.Extensions == nil {
+				operation.Extensions = make(map[string]interface{})
+			}
+			operation.Extensions[key] = value
+		}
+	}
+
+	annotated := annotations != nil && len(annotations.Security) > 0
+	if !annotated && len(security) > 0 {
+		operation.Security = security
+	}
+}
+
+// annotatesResponse reports whether @Success or @Failure documents the response key.
+func annotatesResponse(annotations *Annotation, code string) bool {
+	if annotations == nil {
+		return false
+	}
+	for _, success := range annotations.Successes {
+		if responseKey(success.StatusCode) == code {
+			return true
+		}
+	}
+	for _, failure := range annotations.Failures {
+		if responseKey(failure.StatusCode) == code {
+			return true
+		}
+	}
+	return false
+}
+
+// combineSecurity requires both sets of requirements: each existing alternative is
+// combined with each alternative of next.
+func combineSecurity(current, next []SecurityRequirement) []SecurityRequirement {
+	if len(current) == 0 {
+		return next
+	}
+	combined := make([]SecurityRequirement, 0, len(current)*len(next))
+	for _, a := range current {
+		for _, b := range next {
+			requirement := make(SecurityRequirement, len(a)+len(b))
+			for scheme, scopes := range a {
+				requirement[scheme] = scopes
+			}
+			for scheme, scopes := range b {
+				requirement[scheme] = scopes
+			}
+			combined = append(combined, requirement)
+		}
+	}
+	return combined
+}
+
+// hasParameter reports whether params declares a parameter with the given name and location.
+func hasParameter(params []Parameter, name, in string) bool {
+	for _, p := range params {
+		if p.Name == name && p.In == in {
+			return true
+		}
+	}
+	return false
+}
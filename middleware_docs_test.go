@@ -0,0 +1,216 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func authorizeTestAdmin(next http.Handler) http.Handler { return next }
+
+func requireTestKey(next http.Handler) http.Handler { return next }
+
+func tenantTestMiddleware(next http.Handler) http.Handler { return next }
+
+func rateTestLimit(limit int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return http.NotFoundHandler()
+		}
+		return next
+	}
+}
+
+func requireTestScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if scope == "" {
+			return http.NotFoundHandler()
+		}
+		return next
+	}
+}
+
+// rateTestHandler documents its own 429 response.
+// @Summary Limited
+// @Failure 429 {object} ProblemDetails "Slow down"
+func rateTestHandler(w http.ResponseWriter, r *http.Request) {}
+
+func TestGenerateSpec_MiddlewareDocs(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "middleware_docs_test.go"))
+	t.Cleanup(resetMiddlewareDocsForTesting)
+	DescribeMiddleware(requireTestKey, MiddlewareDoc{
+		Security:  []SecurityRequirement{{"ApiKeyAuth": {}}},
+		Responses: map[string]Response{"401": {Description: "Missing API key"}},
+	})
+	DescribeMiddleware(tenantTestMiddleware, MiddlewareDoc{
+		Security: []SecurityRequirement{{"TenantKey": {}}},
+		Parameters: []Parameter{
+			{Name: "X-Tenant-ID", In: "header", Required: true, Schema: &Schema{Type: "string"}},
+		},
+		Extensions: map[string]interface{}{"x-tenant-scoped": true},
+	})
+	// Every closure returned by one constructor shares its description
+	DescribeMiddleware(rateTestLimit(10), MiddlewareDoc{
+		Responses: map[string]Response{"429": {Description: "Too Many Requests"}},
+	})
+
+	r := chi.NewRouter()
+	r.Use(authorizeTestAdmin)
+	r.Get("/open", func(w http.ResponseWriter, r *http.Request) {})
+	r.With(requireTestKey, tenantTestMiddleware, rateTestLimit(100)).Get("/keyed", func(w http.ResponseWriter, r *http.Request) {})
+	r.With(rateTestLimit(5)).Get("/limited", rateTestHandler)
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+
+	// Undescribed middleware is not guessed from its name
+	AssertEqual(t, 0, len(spec.Paths["/open"].Get.Security))
+
+	keyed := spec.Paths["/keyed"].Get
+	AssertDeepEqual(t, []SecurityRequirement{{"ApiKeyAuth": {}, "TenantKey": {}}}, keyed.Security)
+	AssertEqual(t, "X-Tenant-ID", keyed.Parameters[0].Name)
+	AssertEqual(t, "Missing API key", keyed.Responses["401"].Description)
+	AssertEqual(t, "Too Many Requests", keyed.Responses["429"].Description)
+	AssertEqual(t, true, keyed.Extensions["x-tenant-scoped"])
+
+	// Annotated responses win over middleware responses
+	AssertEqual(t, "Slow down", spec.Paths["/limited"].Get.Responses["429"].Description)
+}
+
+func TestGenerateSpec_DocumentedMiddleware(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "middleware_docs_test.go"))
+	t.Cleanup(resetMiddlewareDocsForTesting)
+	DescribeMiddleware(requireTestScope(""), MiddlewareDoc{Security: []SecurityRequirement{{"OAuth2": {}}}})
+	admin := Documented(requireTestScope("admin"), MiddlewareDoc{Security: []SecurityRequirement{{"OAuth2": {"admin"}}}})
+	read := Documented(requireTestScope("read"), MiddlewareDoc{Security: []SecurityRequirement{{"OAuth2": {"read"}}}})
+
+	r := chi.NewRouter()
+	r.With(admin).Delete("/scoped", func(w http.ResponseWriter, r *http.Request) {})
+	r.With(read).Get("/scoped", func(w http.ResponseWriter, r *http.Request) {})
+	r.With(requireTestScope("write")).Put("/scoped", func(w http.ResponseWriter, r *http.Request) {})
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+
+	item := spec.Paths["/scoped"]
+	AssertDeepEqual(t, []SecurityRequirement{{"OAuth2": {"admin"}}}, item.Delete.Security)
+	AssertDeepEqual(t, []SecurityRequirement{{"OAuth2": {"read"}}}, item.Get.Security)
+	// Undocumented instances fall back to the constructor's registration
+	AssertDeepEqual(t, []SecurityRequirement{{"OAuth2": {}}}, item.Put.Security)
+
+	// Documented middlewares still wrap the handler
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+	Documented(func(h http.Handler) http.Handler { return h }, MiddlewareDoc{})(next).ServeHTTP(nil, nil)
+	AssertEqual(t, true, called)
+}
+
+// authTestMiddleware provides its middleware as a method.
+type authTestMiddleware struct{}
+
+func (a *authTestMiddleware) Require(next http.Handler) http.Handler { return next }
+
+func TestGenerateSpec_MethodValueMiddleware(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "middleware_docs_test.go"))
+	auth := &authTestMiddleware{}
+	g.DescribeMiddleware(auth.Require, MiddlewareDoc{Security: []SecurityRequirement{{"BearerAuth": {}}}})
+
+	r := chi.NewRouter()
+	r.With(auth.Require).Get("/registered", func(w http.ResponseWriter, r *http.Request) {})
+	r.With(Documented(auth.Require, MiddlewareDoc{
+		Security: []SecurityRequirement{{"ApiKeyAuth": {}}},
+	})).Get("/documented", func(w http.ResponseWriter, r *http.Request) {})
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+
+	AssertDeepEqual(t, []SecurityRequirement{{"BearerAuth": {}}}, spec.Paths["/registered"].Get.Security)
+	AssertDeepEqual(t, []SecurityRequirement{{"ApiKeyAuth": {}}}, spec.Paths["/documented"].Get.Security)
+}
+
+func TestGenerateSpec_GeneratorMiddlewareDocs(t *testing.T) {
+	idx := newTestIndex(t, "middleware_docs_test.go")
+	t.Cleanup(resetMiddlewareDocsForTesting)
+	DescribeMiddleware(requireTestKey, MiddlewareDoc{Security: []SecurityRequirement{{"ApiKeyAuth": {}}}})
+
+	partner := NewGenerator(WithTypeIndex(idx), WithMiddlewareDoc(requireTestKey, MiddlewareDoc{
+		Security: []SecurityRequirement{{"PartnerKey": {}}},
+	}))
+	partner.DescribeMiddleware(tenantTestMiddleware, MiddlewareDoc{
+		Parameters: []Parameter{{Name: "X-Tenant-ID", In: "header", Schema: &Schema{Type: "string"}}},
+	})
+	plain := NewGeneratorWithCache(idx)
+
+	r := chi.NewRouter()
+	r.With(requireTestKey, tenantTestMiddleware).Get("/keyed", func(w http.ResponseWriter, r *http.Request) {})
+	cfg := Config{Title: "Test", Version: "1.0.0"}
+
+	// Generator registrations win over the package-level registry
+	keyed := partner.GenerateSpec(r, cfg).Paths["/keyed"].Get
+	AssertDeepEqual(t, []SecurityRequirement{{"PartnerKey": {}}}, keyed.Security)
+	AssertEqual(t, "X-Tenant-ID", keyed.Parameters[0].Name)
+
+	// and do not leak into other generators
+	keyed = plain.GenerateSpec(r, cfg).Paths["/keyed"].Get
+	AssertDeepEqual(t, []SecurityRequirement{{"ApiKeyAuth": {}}}, keyed.Security)
+	AssertEqual(t, 0, len(keyed.Parameters))
+}
+
+func TestGenerateSpec_MiddlewareExtensionKeys(t *testing.T) {
+	doc := MiddlewareDoc{Extensions: map[string]interface{}{
+		"x-rate-limited": true,
+		"responses":      map[string]interface{}{},
+		"security":       []interface{}{},
+	}}
+	g := NewGenerator(WithTypeIndex(newTestIndex(t, "middleware_docs_test.go")), WithMiddlewareDoc(tenantTestMiddleware, doc))
+
+	r := chi.NewRouter()
+	r.With(tenantTestMiddleware).Get("/extended", rateTestHandler)
+	operation := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"}).Paths["/extended"].Get
+	AssertDeepEqual(t, map[string]interface{}{"x-rate-limited": true}, operation.Extensions)
+
+	data, err := json.Marshal(operation)
+	AssertNoError(t, err)
+	var decoded map[string]interface{}
+	AssertNoError(t, json.Unmarshal(data, &decoded))
+	if responses, _ := decoded["responses"].(map[string]interface{}); responses["429"] == nil {
+		t.Errorf("expected the operation's responses to be kept, got %s", data)
+	}
+
+	// Ignored keys are reported
+	strict := NewGenerator(WithTypeIndex(g.schemaGen.typeIndex), WithMiddlewareDoc(tenantTestMiddleware, doc), WithStrict())
+	_, err = strict.GenerateSpecContext(context.Background(), r, Config{Title: "Test", Version: "1.0.0"})
+	var strictErr *StrictModeError
+	if !errors.As(err, &strictErr) || len(strictErr.Messages) != 2 {
+		t.Errorf("expected a problem per ignored key, got %v", err)
+	}
+}
+
+func TestCombineSecurity(t *testing.T) {
+	combined := combineSecurity(
+		[]SecurityRequirement{{"BearerAuth": {}}},
+		[]SecurityRequirement{{"ApiKeyAuth": {}}, {"OAuth2": {"read"}}},
+	)
+	AssertDeepEqual(t, []SecurityRequirement{
+		{"BearerAuth": {}, "ApiKeyAuth": {}},
+		{"BearerAuth": {}, "OAuth2": {"read"}},
+	}, combined)
+}
+
+func TestOperationJSON_Extensions(t *testing.T) {
+	operation := Operation{
+		OperationID: "listUsers",
+		Responses:   map[string]Response{"200": {Description: "OK"}},
+		Extensions:  map[string]interface{}{"x-rate-limit": 100},
+	}
+	data, err := json.Marshal(operation)
+	AssertNoError(t, err)
+
+	var fields map[string]interface{}
+	AssertNoError(t, json.Unmarshal(data, &fields))
+	AssertEqual(t, 100.0, fields["x-rate-limit"])
+	AssertEqual(t, "listUsers", fields["operationId"])
+
+	var decoded Operation
+	AssertNoError(t, json.Unmarshal(data, &decoded))
+	AssertEqual(t, 100.0, decoded.Extensions["x-rate-limit"])
+	AssertEqual(t, "OK", decoded.Responses["200"].Description)
+}
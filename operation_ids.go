@@ -0,0 +1,98 @@
+// Package openapi provides operationId derivation and uniqueness across a spec.
+package openapi
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// OperationIDStrategy selects how operationIds are derived for operations without an @ID.
+type OperationIDStrategy string
+
+const (
+	// OperationIDFromHandler names operations after their handler declaration,
+	// e.g. ListUsers -> "listUsers" and UserHandler.Get -> "userGet".
+	// Closures other than documented handler factories fall back to OperationIDFromRoute.
+	OperationIDFromHandler OperationIDStrategy = "handler"
+	// OperationIDFromRoute combines the method with the static path segments,
+	// e.g. GET /users/{id} -> "getUsers".
+	OperationIDFromRoute OperationIDStrategy = "route"
+)
+
+// operationIDRegistry hands out operationIds that are unique across a spec.
+type operationIDRegistry struct {
+	ids    map[string]bool
+	logger *slog.Logger
+}
+
+// newOperationIDRegistry returns an empty registry that reports duplicate @IDs to logger.
+func newOperationIDRegistry(logger *slog.Logger) *operationIDRegistry {
+	return &operationIDRegistry{ids: make(map[string]bool), logger: logger}
+}
+
+// assign returns a unique operationId for an operation. An @ID is used as declared when free;
+// otherwise the strategy's candidates are tried in order and the last one is numbered
+// ("getUsers2", "getUsers3", ...) until it no longer collides.
+func (r *operationIDRegistry) assign(
+	strategy OperationIDStrategy,
+	info *HandlerInfo,
+	annotations *Annotation,
+	method, route string,
+) string {
+	var candidates []string
+	if annotations != nil && annotations.OperationID != "" {
+		candidates = append(candidates, annotations.OperationID)
+	} else {
+		// Closures are only named after documented handler factories, not route setup functions
+		if strategy != OperationIDFromRoute && (info == nil || !info.Closure || annotations != nil) {
+			if id := handlerOperationID(info); id != "" {
+				candidates = append(candidates, id)
+			}
+		}
+		candidates = append(candidates, generateOperationID(method, route))
+	}
+
+	for _, id := range candidates {
+		if !r.ids[id] {
+			r.ids[id] = true
+			return id
+		}
+	}
+
+	base := candidates[len(candidates)-1]
+	if annotations != nil && annotations.OperationID != "" {
+		r.logger.Warn("[openapi] operationIDRegistry: duplicate @ID", "id", base, "method", method, "route", route)
+	}
+	for n := 2; ; n++ {
+		id := base + strconv.Itoa(n)
+		if !r.ids[id] {
+			r.ids[id] = true
+			return id
+		}
+	}
+}
+
+// handlerOperationID derives an operationId from the handler declaration. Receiver types drop a
+// trailing "Handler", so UserHandler.List becomes "userList".
+func handlerOperationID(info *HandlerInfo) string {
+	if info == nil || info.FunctionName == "" {
+		return ""
+	}
+	name := info.FunctionName
+	if info.Receiver != "" {
+		name = strings.TrimSuffix(info.Receiver, "Handler") + name
+	}
+	return lowerFirst(name)
+}
+
+// lowerFirst returns the string with its first rune lowercased.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
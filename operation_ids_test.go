@@ -0,0 +1,81 @@
+package openapi
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// listTestAccounts lists accounts.
+// @ID listAccounts
+// @Summary List accounts
+func listTestAccounts(w http.ResponseWriter, r *http.Request) {}
+
+// getTestAccount returns one account.
+// @Summary Get account
+func getTestAccount(w http.ResponseWriter, r *http.Request) {}
+
+// legacyTestAccount is mounted on a catch-all pattern but published under its real path.
+// @ID getAccountLegacy
+// @Router /accounts/{id}/legacy [get]
+func legacyTestAccount(w http.ResponseWriter, r *http.Request) {}
+
+func TestOperationIDRegistry_Assign(t *testing.T) {
+	ids := newOperationIDRegistry(slog.Default())
+	handler := &HandlerInfo{FunctionName: "ListUsers"}
+	method := &HandlerInfo{FunctionName: "Get", Receiver: "UserHandler"}
+	inline := &HandlerInfo{FunctionName: "Routes", Closure: true}
+
+	AssertEqual(t, "listUsers", ids.assign("", handler, nil, "GET", "/users"))
+	AssertEqual(t, "userGet", ids.assign("", method, nil, "GET", "/users/{id}"))
+	// Same handler on another route falls back to the route-based id
+	AssertEqual(t, "getV2Users", ids.assign("", handler, nil, "GET", "/v2/users"))
+	AssertEqual(t, "getV2Users2", ids.assign("", handler, nil, "GET", "/v2/users/{id}"))
+	// Inline closures are not named after their enclosing function
+	AssertEqual(t, "postUsers", ids.assign("", inline, nil, "POST", "/users"))
+	// Explicit IDs win, and duplicates are numbered
+	AssertEqual(t, "createUser", ids.assign("", handler, &Annotation{OperationID: "createUser"}, "PUT", "/users"))
+	AssertEqual(t, "createUser2", ids.assign("", handler, &Annotation{OperationID: "createUser"}, "PATCH", "/users"))
+	// The route strategy ignores handler names
+	AssertEqual(t, "deleteUsers", ids.assign(OperationIDFromRoute, handler, nil, "DELETE", "/users/{id}"))
+}
+
+func TestGenerateSpec_UniqueOperationIDs(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "operation_ids_test.go"))
+
+	r := chi.NewRouter()
+	r.Get("/accounts", listTestAccounts)
+	r.Get("/accounts/{id}", getTestAccount)
+	r.Get("/accounts/{id}/*", legacyTestAccount)
+	r.Get("/inline", func(w http.ResponseWriter, r *http.Request) {})
+	r.Get("/inline/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+
+	AssertEqual(t, "listAccounts", spec.Paths["/accounts"].Get.OperationID)
+	AssertEqual(t, "getTestAccount", spec.Paths["/accounts/{id}"].Get.OperationID)
+	AssertEqual(t, "getInline", spec.Paths["/inline"].Get.OperationID)
+	AssertEqual(t, "getInline2", spec.Paths["/inline/{id}"].Get.OperationID)
+
+	legacy := spec.Paths["/accounts/{id}/legacy"]
+	if legacy == nil {
+		t.Fatalf("expected @Router path to be published, got paths %v", spec.Paths)
+	}
+	AssertEqual(t, "getAccountLegacy", legacy.Get.OperationID)
+	if _, ok := spec.Paths["/accounts/{id}/*"]; ok {
+		t.Error("expected chi pattern to be replaced by @Router path")
+	}
+}
+
+func TestGenerateSpec_RouteOperationIDStrategy(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "operation_ids_test.go"))
+
+	r := chi.NewRouter()
+	r.Get("/accounts", listTestAccounts)
+	r.Get("/accounts/{id}", getTestAccount)
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0", OperationIDStrategy: OperationIDFromRoute})
+
+	AssertEqual(t, "listAccounts", spec.Paths["/accounts"].Get.OperationID)
+	AssertEqual(t, "getAccounts", spec.Paths["/accounts/{id}"].Get.OperationID)
+}
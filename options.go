@@ -0,0 +1,192 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Option configures a Generator created with NewGenerator.
+type Option func(*generatorOptions)
+
+// generatorOptions collects the options before the Generator is assembled, so the result does
+// not depend on the order of independent options.
+type generatorOptions struct {
+	roots          []string
+	typeIndex      *TypeIndex
+	logger         *slog.Logger
+	knownTypePacks []map[string]*Schema
+	noDefaultTypes bool
+	naming         SchemaNaming
+	hooks          Hooks
+	strict         bool
+	middlewareDocs []middlewareEntry
+}
+
+// Hooks are called while a spec is generated. They may modify the value they receive.
+// Hooks run while the Generator is busy and must not call GenerateSpec on the same Generator.
+type Hooks struct {
+	OnOperation func(route RouteInfo, operation *Operation) // after an operation and its operationId are built
+	OnSchema    func(name string, schema *Schema)           // before a schema is added to the components
+	OnSpec      func(spec *Spec)                            // after the spec is complete
+}
+
+// WithRoots indexes the given directories instead of the project containing the working
+// directory. Package paths are resolved against the go.mod at or above the first root.
+func WithRoots(roots ...string) Option {
+	return func(o *generatorOptions) {
+		o.roots = append(o.roots, roots...)
+	}
+}
+
+// WithTypeIndex uses an index that has already been built, e.g. one shared by several generators.
+// It takes precedence over WithRoots.
+func WithTypeIndex(idx *TypeIndex) Option {
+	return func(o *generatorOptions) {
+		o.typeIndex = idx
+	}
+}
+
+// WithLogger sends the generator's log output to logger instead of slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *generatorOptions) {
+		o.logger = logger
+	}
+}
+
+// WithKnownTypes adds a pack of schemas for external types, keyed by qualified name
+// (e.g. "decimal.Decimal"). Later packs override earlier ones and the built-in types.
+func WithKnownTypes(pack map[string]*Schema) Option {
+	return func(o *generatorOptions) {
+		o.knownTypePacks = append(o.knownTypePacks, pack)
+	}
+}
+
+// WithoutDefaultKnownTypes drops the built-in external types (time.Time, uuid.UUID, ...) and the
+// types registered with the package-level AddExternalKnownType, leaving only WithKnownTypes packs.
+func WithoutDefaultKnownTypes() Option {
+	return func(o *generatorOptions) {
+		o.noDefaultTypes = true
+	}
+}
+
+// WithSchemaNaming sets how component schemas are named (default: QualifiedSchemaNames).
+func WithSchemaNaming(naming SchemaNaming) Option {
+	return func(o *generatorOptions) {
+		o.naming = naming
+	}
+}
+
+// WithHooks sets callbacks to adjust operations, schemas and the finished spec.
+func WithHooks(hooks Hooks) Option {
+	return func(o *generatorOptions) {
+		o.hooks = hooks
+	}
+}
+
+// WithMiddlewareDoc documents a middleware for this generator only, taking precedence over the
+// package-level DescribeMiddleware. See DescribeMiddleware for how middlewares are identified.
+func WithMiddlewareDoc(mw func(http.Handler) http.Handler, doc MiddlewareDoc) Option {
+	return func(o *generatorOptions) {
+		o.middlewareDocs = append(o.middlewareDocs, middlewareEntry{mw: mw, doc: doc})
+	}
+}
+
+// WithStrict makes GenerateSpecContext return a *StrictModeError when generation logs warnings,
+// such as malformed annotations, duplicate operations or undeclared security schemes.
+func WithStrict() Option {
+	return func(o *generatorOptions) {
+		o.strict = true
+	}
+}
+
+// knownTypes returns the generator's initial known types with the configured packs applied.
+func (o *generatorOptions) knownTypes() map[string]*Schema {
+	types := make(map[string]*Schema)
+	if !o.noDefaultTypes {
+		types = newKnownTypes()
+	}
+	for _, pack := range o.knownTypePacks {
+		for name, schema := range pack {
+			copied := *schema
+			types[name] = &copied
+		}
+	}
+	return types
+}
+
+// StrictModeError lists the problems that made generation fail in strict mode.
+type StrictModeError struct {
+	Messages []string
+}
+
+func (e *StrictModeError) Error() string {
+	return "strict mode: " + strings.Join(e.Messages, "; ")
+}
+
+// problemLog collects the warnings logged during one generation.
+type problemLog struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+// reset drops the collected messages and returns them.
+func (p *problemLog) reset() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	messages := p.messages
+	p.messages = nil
+	return messages
+}
+
+func (p *problemLog) add(message string) {
+	p.mutex.Lock()
+	p.messages = append(p.messages, message)
+	p.mutex.Unlock()
+}
+
+// problemHandler is a slog.Handler that records warnings and errors before passing records on.
+type problemHandler struct {
+	slog.Handler
+	problems *problemLog
+	attrs    []slog.Attr
+}
+
+func (h problemHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelWarn || h.Handler.Enabled(ctx, level)
+}
+
+func (h problemHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn {
+		var b strings.Builder
+		b.WriteString(strings.TrimPrefix(r.Message, "[openapi] "))
+		appendAttr := func(a slog.Attr) bool {
+			fmt.Fprintf(&b, " %s=%v", a.Key, a.Value)
+			return true
+		}
+		for _, a := range h.attrs {
+			appendAttr(a)
+		}
+		r.Attrs(appendAttr)
+		h.problems.add(b.String())
+	}
+	if !h.Handler.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h problemHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return problemHandler{
+		Handler:  h.Handler.WithAttrs(attrs),
+		problems: h.problems,
+		attrs:    append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...),
+	}
+}
+
+func (h problemHandler) WithGroup(name string) slog.Handler {
+	return problemHandler{Handler: h.Handler.WithGroup(name), problems: h.problems, attrs: h.attrs}
+}
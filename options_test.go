@@ -0,0 +1,274 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// OptionsTestPrice is documented through a known type pack.
+type OptionsTestPrice string
+
+// OptionsTestWidget is returned by the options test handlers.
+type OptionsTestWidget struct {
+	ID    string           `json:"id"`
+	Price OptionsTestPrice `json:"price"`
+}
+
+// getTestOptionsWidget returns a widget.
+// @Summary Get widget
+// @Tags widgets
+// @Success 200 {object} OptionsTestWidget
+func getTestOptionsWidget(w http.ResponseWriter, r *http.Request) {}
+
+// NamingTestFriend is referenced from NamingTestUser.
+type NamingTestFriend struct {
+	Name string `json:"name"`
+}
+
+// NamingTestUser references another component schema.
+type NamingTestUser struct {
+	Friend  NamingTestFriend   `json:"friend"`
+	Friends []NamingTestFriend `json:"friends"`
+}
+
+// getTestNamingUser returns a user.
+// @Success 200 {object} NamingTestUser
+func getTestNamingUser(w http.ResponseWriter, r *http.Request) {}
+
+// getTestNamingFriend returns a friend from another package.
+// @Success 200 {object} billing.NamingTestFriend
+func getTestNamingFriend(w http.ResponseWriter, r *http.Request) {}
+
+func optionsTestRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/widgets/{id}", getTestOptionsWidget)
+	return r
+}
+
+func TestNewGenerator_NamingAndKnownTypes(t *testing.T) {
+	g := NewGenerator(
+		WithTypeIndex(newTestIndex(t, "options_test.go")),
+		WithSchemaNaming(ShortSchemaNames),
+		WithKnownTypes(map[string]*Schema{"openapi.OptionsTestPrice": {Type: "string", Format: "decimal"}}),
+	)
+	spec := g.GenerateSpec(optionsTestRouter(), Config{Title: "Test", Version: "1.0.0"})
+
+	widget, ok := spec.Components.Schemas["OptionsTestWidget"]
+	if !ok {
+		t.Fatalf("expected short schema name, got %v", spec.Components.Schemas)
+	}
+	AssertEqual(t, "decimal", widget.Properties["price"].Format)
+	response := spec.Paths["/widgets/{id}"].Get.Responses["200"]
+	AssertEqual(t, schemaRefPrefix+"OptionsTestWidget", response.Content["application/json"].Schema.Ref)
+	if _, ok := spec.Components.Schemas["ProblemDetails"]; !ok {
+		t.Error("expected the standard ProblemDetails schema")
+	}
+}
+
+func TestNewGenerator_WithoutDefaultKnownTypes(t *testing.T) {
+	pack := map[string]*Schema{"money.Amount": {Type: "string"}}
+	g := NewGenerator(WithTypeIndex(&TypeIndex{}), WithoutDefaultKnownTypes(), WithKnownTypes(pack))
+	if _, ok := g.schemaGen.knownType("time.Time"); ok {
+		t.Error("expected built-in known types to be dropped")
+	}
+	if _, ok := g.schemaGen.knownType("money.Amount"); !ok {
+		t.Error("expected known type from pack")
+	}
+	pack["money.Amount"].Type = "number"
+	amount, _ := g.schemaGen.knownType("money.Amount")
+	AssertEqual(t, "string", amount.Type)
+}
+
+func TestShortSchemaNames(t *testing.T) {
+	AssertEqual(t, "User", ShortSchemaNames("models.User"))
+	AssertEqual(t, "Page_User", ShortSchemaNames("models.Page_User"))
+	AssertEqual(t, "ProblemDetails", ShortSchemaNames("ProblemDetails"))
+
+	// Every member of a colliding group keeps its qualified name, whatever the order
+	sg := &SchemaGenerator{naming: ShortSchemaNames}
+	want := map[string]string{
+		"models.User":    "models.User",
+		"billing.User":   "billing.User",
+		"models.Account": "Account",
+		"ProblemDetails": "ProblemDetails",
+	}
+	AssertDeepEqual(t, want, sg.componentNames([]string{"models.User", "billing.User", "models.Account", "ProblemDetails"}))
+	AssertDeepEqual(t, want, sg.componentNames([]string{"ProblemDetails", "models.Account", "billing.User", "models.User"}))
+}
+
+func TestNewGenerator_ShortSchemaNamesReused(t *testing.T) {
+	g := NewGenerator(WithTypeIndex(newTestIndex(t, "options_test.go")), WithSchemaNaming(ShortSchemaNames))
+	cfg := Config{Title: "Test", Version: "1.0.0"}
+	r := chi.NewRouter()
+	r.Get("/users", getTestNamingUser)
+
+	first := g.GenerateSpec(r, cfg)
+	AssertEqual(t, schemaRefPrefix+"NamingTestFriend", first.Components.Schemas["NamingTestUser"].Properties["friend"].Ref)
+
+	// A colliding type in a later spec must not leave the earlier short name behind
+	r.Get("/friends", getTestNamingFriend)
+	second := g.GenerateSpec(r, cfg)
+	user := second.Components.Schemas["NamingTestUser"]
+	AssertEqual(t, schemaRefPrefix+"openapi.NamingTestFriend", user.Properties["friend"].Ref)
+	AssertEqual(t, schemaRefPrefix+"openapi.NamingTestFriend", user.Properties["friends"].Items.Ref)
+	if _, ok := second.Components.Schemas["openapi.NamingTestFriend"]; !ok {
+		t.Errorf("expected qualified component, got %v", second.Components.Schemas)
+	}
+
+	// Specs already returned are not modified
+	AssertEqual(t, schemaRefPrefix+"NamingTestFriend", first.Components.Schemas["NamingTestUser"].Properties["friend"].Ref)
+	third := g.GenerateSpec(r, cfg)
+	AssertEqual(t, schemaRefPrefix+"openapi.NamingTestFriend", third.Components.Schemas["NamingTestUser"].Properties["friend"].Ref)
+}
+
+func TestNewGenerator_CollidingSchemaNames(t *testing.T) {
+	naming := func(qualifiedName string) string {
+		if qualifiedName == "ProblemDetails" || qualifiedName == "openapi.OptionsTestWidget" {
+			return "Shared"
+		}
+		return ShortSchemaNames(qualifiedName)
+	}
+	g := NewGenerator(WithTypeIndex(newTestIndex(t, "options_test.go")), WithSchemaNaming(naming))
+	spec := g.GenerateSpec(optionsTestRouter(), Config{Title: "Test", Version: "1.0.0"})
+
+	if _, ok := spec.Components.Schemas["Shared"]; ok {
+		t.Fatal("expected colliding schemas to keep their qualified names")
+	}
+	widget := spec.Paths["/widgets/{id}"].Get
+	AssertEqual(t, schemaRefPrefix+"openapi.OptionsTestWidget", widget.Responses["200"].Content["application/json"].Schema.Ref)
+	AssertEqual(t, schemaRefPrefix+"ProblemDetails", widget.Responses["500"].Content["application/problem+json"].Schema.Ref)
+	for name := range spec.Components.Schemas {
+		if name != "ProblemDetails" && name != "openapi.OptionsTestWidget" && strings.Contains(name, ".") {
+			t.Errorf("expected %s to get its short name", name)
+		}
+	}
+}
+
+func TestNewGenerator_Hooks(t *testing.T) {
+	var schemas []string
+	g := NewGenerator(
+		WithTypeIndex(newTestIndex(t, "options_test.go")),
+		WithHooks(Hooks{
+			OnOperation: func(route RouteInfo, operation *Operation) {
+				operation.Description = "route " + route.Pattern
+			},
+			OnSchema: func(name string, schema *Schema) {
+				schemas = append(schemas, name)
+				schema.Description = "hooked"
+			},
+			OnSpec: func(spec *Spec) {
+				spec.Info.Description = "complete"
+			},
+		}),
+	)
+	spec := g.GenerateSpec(optionsTestRouter(), Config{Title: "Test", Version: "1.0.0"})
+
+	AssertEqual(t, "route /widgets/{id}", spec.Paths["/widgets/{id}"].Get.Description)
+	sort.Strings(schemas)
+	AssertDeepEqual(t, []string{"openapi.OptionsTestPrice", "openapi.OptionsTestWidget"}, schemas)
+	AssertEqual(t, "hooked", spec.Components.Schemas["openapi.OptionsTestWidget"].Description)
+	AssertEqual(t, "complete", spec.Info.Description)
+}
+
+func TestGenerateSpecContext_Strict(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	g := NewGenerator(WithTypeIndex(newTestIndex(t, "options_test.go")), WithLogger(logger), WithStrict())
+	cfg := Config{Title: "Test", Version: "1.0.0", Security: []SecurityRequirement{{"MissingAuth": {}}}}
+
+	spec, err := g.GenerateSpecContext(context.Background(), optionsTestRouter(), cfg)
+	var strictErr *StrictModeError
+	if !errors.As(err, &strictErr) {
+		t.Fatalf("expected StrictModeError, got %v", err)
+	}
+	AssertEqual(t, 1, len(strictErr.Messages))
+	if !strings.Contains(strictErr.Messages[0], "scheme=MissingAuth") {
+		t.Errorf("unexpected problem %q", strictErr.Messages[0])
+	}
+	AssertEqual(t, 1, len(spec.Paths))
+	if !strings.Contains(logs.String(), "MissingAuth") {
+		t.Error("expected the warning to reach the configured logger")
+	}
+
+	cfg.Security = nil
+	_, err = g.GenerateSpecContext(context.Background(), optionsTestRouter(), cfg)
+	AssertNoError(t, err)
+
+	// HandleFunc also registers CONNECT, which is skipped without failing strict mode
+	r := optionsTestRouter()
+	r.HandleFunc("/widgets", getTestOptionsWidget)
+	spec, err = g.GenerateSpecContext(context.Background(), r, cfg)
+	AssertNoError(t, err)
+	AssertEqual(t, true, spec.Paths["/widgets"].Get != nil)
+}
+
+func TestGenerateSpecContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cfg := Config{Title: "Test", Version: "1.0.0"}
+
+	if _, err := BuildTypeIndexContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected indexing to be cancelled, got %v", err)
+	}
+	if _, err := NewGenerator(WithRoots(".")).GenerateSpecContext(ctx, optionsTestRouter(), cfg); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected generation to stop while indexing, got %v", err)
+	}
+	g := NewGenerator(WithTypeIndex(newTestIndex(t, "options_test.go")))
+	if _, err := g.GenerateSpecContext(ctx, optionsTestRouter(), cfg); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected generation to stop before the routes, got %v", err)
+	}
+}
+
+func TestBuildTypeIndexContext_Roots(t *testing.T) {
+	dir := t.TempDir()
+	AssertNoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n\ngo 1.24\n"), 0o644))
+	AssertNoError(t, os.Mkdir(filepath.Join(dir, "models"), 0o755))
+	source := "package models\n\ntype Item struct {\n\tName string `json:\"name\"`\n}\n"
+	AssertNoError(t, os.WriteFile(filepath.Join(dir, "models", "item.go"), []byte(source), 0o644))
+
+	idx, err := BuildTypeIndexContext(context.Background(), filepath.Join(dir, "models"))
+	AssertNoError(t, err)
+	AssertEqual(t, dir, idx.root)
+	AssertEqual(t, "example.com/shop", idx.modulePath)
+	if idx.LookupQualifiedType("models.Item") == nil {
+		t.Error("expected type from the root to be indexed")
+	}
+	if idx.LookupQualifiedType("openapi.Spec") != nil {
+		t.Error("expected only the given roots to be indexed")
+	}
+}
+
+func TestNewGenerator_IndexingUsesLogger(t *testing.T) {
+	dir := t.TempDir()
+	AssertNoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n\ngo 1.24\n"), 0o644))
+	AssertNoError(t, os.WriteFile(filepath.Join(dir, "item.go"), []byte("package shop\n\ntype Item struct{}\n"), 0o644))
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	NewGenerator(WithRoots(dir), WithLogger(logger)).GenerateSpec(chi.NewRouter(), Config{Title: "Test", Version: "1.0.0"})
+	if !strings.Contains(logs.String(), "BuildTypeIndex: completed") {
+		t.Errorf("expected indexing to log to the generator's logger, got %s", logs.String())
+	}
+}
+
+func TestNewGenerator_AnnotationParsingUsesLogger(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	g := NewGenerator(WithTypeIndex(newTestIndex(t, "options_test.go")), WithLogger(logger))
+	g.GenerateSpec(optionsTestRouter(), Config{Title: "Test", Version: "1.0.0"})
+	for _, message := range []string{"findFuncDecl: found function with doc", "parseSuccessAnnotation: called"} {
+		if !strings.Contains(logs.String(), message) {
+			t.Errorf("expected %q in the generator's logger", message)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+// Package openapi provides operation parameter generation from @Param annotations.
+package openapi
+
+import (
+	"go/ast"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// buildParameter converts a non-body @Param annotation into an OpenAPI parameter,
+// applying attributes such as default(), enums() and collectionFormat().
+func (g *Generator) buildParameter(param ParamAnnotation) Parameter {
+	g.log().Debug("[openapi] buildParameter: called", "name", param.Name, "in", param.In, "type", param.Type)
+	parameter := Parameter{
+		Name:        param.Name,
+		In:          param.In,
+		Description: param.Description,
+		Required:    param.Required,
+		Deprecated:  param.Deprecated,
+		Schema:      g.paramSchema(param.Type),
+	}
+
+	applyArrayStyle(&parameter, g.log())
+	applyParamAttributes(&parameter, param, g.log())
+	return parameter
+}
+
+// applyArrayStyle sets the serialization of array parameters: repeated in queries and
+// cookies, comma-separated elsewhere.
+func applyArrayStyle(parameter *Parameter, logger *slog.Logger) {
+	if parameter.Schema.Type != "array" {
+		return
+	}
+	format := "csv"
+	if parameter.In == "query" || parameter.In == "cookie" {
+		format = "multi"
+	}
+	parameter.Style, parameter.Explode = collectionFormatStyle(format, parameter.In, logger)
+}
+
+// paramTagKeys lists the struct tags consulted for a parameter's name, in priority order.
+var paramTagKeys = map[string][]string{
+	"query":  {"query", "form", "schema", "json"},
+	"header": {"header", "json"},
+	"cookie": {"cookie", "json"},
+}
+
+// expandStructParameters walks a query/header/cookie struct via the TypeIndex and emits one
+// parameter per exported field. Names come from the location's struct tags, embedded structs
+// are flattened, fields are required when validated as "required", and constraints come
+// from the openapi/validate tags via applyEnhancedTags.
+func (g *Generator) expandStructParameters(param ParamAnnotation) []Parameter {
+	g.log().Debug("[openapi] expandStructParameters: called", "in", param.In, "type", param.Type)
+	structType := g.lookupStruct(strings.TrimPrefix(param.Type, "*"))
+	if structType == nil {
+		g.log().Warn("[openapi] expandStructParameters: struct type not found", "type", param.Type)
+		return nil
+	}
+	return g.structFieldParameters(structType, param.In, 0)
+}
+
+// maxEmbedDepth bounds recursion through embedded structs.
+const maxEmbedDepth = 8
+
+// structFieldParameters converts the fields of a struct into parameters at the given location.
+func (g *Generator) structFieldParameters(structType *ast.StructType, in string, depth int) []Parameter {
+	var params []Parameter
+	for _, field := range structType.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			tag = strings.Trim(field.Tag.Value, "`")
+		}
+
+		// Flatten embedded structs such as shared pagination parameters
+		if len(field.Names) == 0 {
+			if depth < maxEmbedDepth {
+				if embedded := g.lookupStruct(typeExprName(field.Type)); embedded != nil {
+					params = append(params, g.structFieldParameters(embedded, in, depth+1)...)
+				}
+			}
+			continue
+		}
+
+		fieldName := field.Names[0].Name
+		if !ast.IsExported(fieldName) || !visibleTo(tagAudiences(tag), g.schemaGen.audience) {
+			continue
+		}
+		name := paramTagName(tag, in)
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = fieldName
+		}
+
+		schema := *g.schemaGen.convertFieldType(field.Type)
+		g.schemaGen.applyEnhancedTags(&schema, tag)
+		parameter := Parameter{
+			Name:        name,
+			In:          in,
+			Description: fieldDescription(field),
+			Required:    hasRequiredValidation(tag),
+			Schema:      &schema,
+		}
+		if schema.Deprecated != nil && *schema.Deprecated {
+			parameter.Deprecated = true
+		}
+		applyArrayStyle(&parameter, g.log())
+		params = append(params, parameter)
+	}
+	return params
+}
+
+// lookupStruct finds a struct type by (optionally qualified) name in the TypeIndex.
+func (g *Generator) lookupStruct(typeName string) *ast.StructType {
+	if typeName == "" || g.schemaGen.typeIndex == nil {
+		return nil
+	}
+	ts := g.schemaGen.typeIndex.LookupQualifiedType(g.schemaGen.getQualifiedTypeName(typeName))
+	if ts == nil {
+		return nil
+	}
+	structType, _ := ts.Type.(*ast.StructType)
+	return structType
+}
+
+// typeExprName returns the name of an identifier, pointer or selector type expression.
+func typeExprName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return typeExprName(t.X)
+	case *ast.SelectorExpr:
+		if ident, ok := t.X.(*ast.Ident); ok {
+			return ident.Name + "." + t.Sel.Name
+		}
+	}
+	return ""
+}
+
+// paramTagName returns the parameter name declared by the first matching struct tag.
+func paramTagName(tag, in string) string {
+	for _, key := range paramTagKeys[in] {
+		if value := extractTag(tag, key); value != "" {
+			if comma := strings.Index(value, ","); comma != -1 {
+				value = value[:comma]
+			}
+			if value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// hasRequiredValidation reports whether the validate or binding tag marks a field as required.
+func hasRequiredValidation(tag string) bool {
+	for _, key := range []string{"validate", "binding"} {
+		for _, rule := range strings.Split(extractTag(tag, key), ",") {
+			if rule == "required" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldDescription returns the field's doc or line comment as a description.
+func fieldDescription(field *ast.Field) string {
+	if field.Doc != nil {
+		return strings.TrimSpace(field.Doc.Text())
+	}
+	if field.Comment != nil {
+		return strings.TrimSpace(field.Comment.Text())
+	}
+	return ""
+}
+
+// openapiPrimitiveTypes are OpenAPI type names accepted in place of Go types, e.g. {integer}.
+var openapiPrimitiveTypes = map[string]bool{"string": true, "integer": true, "number": true, "boolean": true}
+
+// paramSchema resolves a parameter's Go type through the SchemaGenerator.
+// "file" becomes a binary string, primitives and OpenAPI type names are inlined, slices become arrays, known external types (time.Time, uuid.UUID)
+// use their registered mappings and named types such as string enums become $refs.
+// The result is always a fresh copy so attributes never leak into shared schemas.
+func (g *Generator) paramSchema(goType string) *Schema {
+	goType = strings.TrimPrefix(goType, "*")
+	if goType == "file" {
+		return &Schema{Type: "string", Format: "binary"}
+	}
+	if strings.HasPrefix(goType, "[]") {
+		return &Schema{Type: "array", Items: g.paramSchema(strings.TrimPrefix(goType, "[]"))}
+	}
+	if openapiPrimitiveTypes[goType] {
+		return &Schema{Type: goType}
+	}
+	if openapiType := mapGoTypeToOpenAPI(goType); openapiType != "object" {
+		return &Schema{Type: openapiType}
+	}
+
+	schema := *g.schemaGen.GenerateSchema(goType)
+	return &schema
+}
+
+// applyParamAttributes maps @Param attributes onto the parameter and its schema.
+// For array parameters, enums, format and bounds constrain the items.
+func applyParamAttributes(parameter *Parameter, param ParamAnnotation, logger *slog.Logger) {
+	schema := parameter.Schema
+	target := schema
+	if schema.Type == "array" && schema.Items != nil {
+		target = schema.Items
+	}
+
+	if param.Default != "" {
+		schema.Default = convertParamValue(param.Default, schema.Type)
+	}
+	if param.Example != "" {
+		parameter.Example = convertParamValue(param.Example, schema.Type)
+	}
+	if len(param.Enums) > 0 {
+		target.Enum = make([]interface{}, len(param.Enums))
+		for i, enum := range param.Enums {
+			target.Enum[i] = convertParamValue(enum, target.Type)
+		}
+	}
+	if param.Format != "" {
+		target.Format = param.Format
+	}
+	if param.Minimum != nil {
+		target.Minimum = param.Minimum
+	}
+	if param.Maximum != nil {
+		target.Maximum = param.Maximum
+	}
+	if param.MinLength != nil {
+		target.MinLength = param.MinLength
+	}
+	if param.MaxLength != nil {
+		target.MaxLength = param.MaxLength
+	}
+	if param.CollectionFormat != "" {
+		parameter.Style, parameter.Explode = collectionFormatStyle(param.CollectionFormat, param.In, logger)
+	}
+}
+
+// convertParamValue types an attribute value according to the schema type,
+// falling back to the raw string when it does not parse.
+func convertParamValue(value, schemaType string) interface{} {
+	switch schemaType {
+	case "integer":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// collectionFormatStyle maps a Swagger 2.0 collectionFormat onto OpenAPI 3 style/explode.
+func collectionFormatStyle(format, in string, logger *slog.Logger) (string, *bool) {
+	explode := false
+	switch format {
+	case "multi":
+		explode = true
+		return "form", &explode
+	case "ssv":
+		return "spaceDelimited", &explode
+	case "pipes":
+		return "pipeDelimited", &explode
+	case "csv":
+		if in == "path" || in == "header" {
+			return "simple", &explode
+		}
+		return "form", &explode
+	}
+	logger.Debug("[openapi] collectionFormatStyle: unsupported collection format", "format", format)
+	return "", nil
+}
+
+// hasFormParams reports whether the annotations declare formData parameters.
+func hasFormParams(annotations *Annotation) bool {
+	if annotations == nil {
+		return false
+	}
+	for _, param := range annotations.Parameters {
+		if param.In == "formData" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildFormRequestBody folds formData parameters into an object schema served as
+// multipart/form-data when files are uploaded and application/x-www-form-urlencoded otherwise,
+// unless @Accept lists the media types. Part content types and explicit collection formats
+// are described with Encoding entries.
+func (g *Generator) buildFormRequestBody(annotations *Annotation) *RequestBody {
+	g.log().Debug("[openapi] buildFormRequestBody: called")
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	encoding := make(map[string]Encoding)
+	hasFile := false
+
+	for _, param := range annotations.Parameters {
+		if param.In != "formData" {
+			continue
+		}
+		parameter := g.buildParameter(param)
+		property := parameter.Schema
+		property.Description = param.Description
+		if parameter.Example != nil {
+			property.Example = parameter.Example
+		}
+		if param.Deprecated {
+			deprecated := true
+			property.Deprecated = &deprecated
+		}
+		schema.Properties[param.Name] = property
+		if param.Required {
+			schema.Required = append(schema.Required, param.Name)
+		}
+
+		isFile := isBinarySchema(property)
+		hasFile = hasFile || isFile
+		part := Encoding{ContentType: param.ContentType}
+		if part.ContentType == "" && isFile {
+			part.ContentType = "application/octet-stream"
+		}
+		if len(param.PartHeaders) > 0 {
+			part.Headers = make(map[string]*Header, len(param.PartHeaders))
+			for _, name := range param.PartHeaders {
+				part.Headers[name] = &Header{Schema: &Schema{Type: "string"}}
+			}
+		}
+		if param.CollectionFormat != "" {
+			part.Style, part.Explode = collectionFormatStyle(param.CollectionFormat, "query", g.log())
+		}
+		if part.ContentType != "" || part.Headers != nil || part.Style != "" {
+			encoding[param.Name] = part
+		}
+	}
+
+	mediaTypes := annotations.Accept
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/x-www-form-urlencoded"}
+		if hasFile {
+			mediaTypes = []string{"multipart/form-data"}
+		}
+	}
+
+	content := make(map[string]MediaTypeObject, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		media := MediaTypeObject{Schema: schema}
+		if len(encoding) > 0 && isFormMediaType(mediaType) {
+			media.Encoding = encoding
+		}
+		content[mediaType] = media
+	}
+
+	return &RequestBody{
+		Description: "Form data",
+		Required:    len(schema.Required) > 0,
+		Content:     content,
+	}
+}
+
+// isBinarySchema reports whether a schema describes a file or a list of files.
+func isBinarySchema(schema *Schema) bool {
+	if schema.Type == "array" && schema.Items != nil {
+		schema = schema.Items
+	}
+	return schema.Type == "string" && schema.Format == "binary"
+}
+
+// isFormMediaType reports whether Encoding objects apply to the media type.
+func isFormMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "multipart/") || mediaType == "application/x-www-form-urlencoded"
+}
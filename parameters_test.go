@@ -0,0 +1,249 @@
+package openapi
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestBuildParameter_Attributes(t *testing.T) {
+	g := NewTestGenerator()
+	min, max := 1.0, 100.0
+	param := g.buildParameter(ParamAnnotation{
+		Name:        "page",
+		In:          "query",
+		Type:        "int",
+		Description: "Page number",
+		Default:     "1",
+		Example:     "3",
+		Minimum:     &min,
+		Maximum:     &max,
+		Deprecated:  true,
+	})
+
+	AssertEqual(t, "integer", param.Schema.Type)
+	AssertEqual(t, interface{}(int64(1)), param.Schema.Default)
+	AssertEqual(t, interface{}(int64(3)), param.Example)
+	AssertEqual(t, 1.0, *param.Schema.Minimum)
+	AssertEqual(t, 100.0, *param.Schema.Maximum)
+	AssertEqual(t, true, param.Deprecated)
+}
+
+func TestBuildParameter_Enums(t *testing.T) {
+	g := NewTestGenerator()
+	param := g.buildParameter(ParamAnnotation{Name: "active", In: "query", Type: "bool", Enums: []string{"true", "false"}})
+	AssertDeepEqual(t, []interface{}{true, false}, param.Schema.Enum)
+}
+
+func TestCollectionFormatStyle(t *testing.T) {
+	tests := []struct {
+		format  string
+		in      string
+		style   string
+		explode bool
+	}{
+		{"multi", "query", "form", true},
+		{"csv", "query", "form", false},
+		{"csv", "path", "simple", false},
+		{"ssv", "query", "spaceDelimited", false},
+		{"pipes", "query", "pipeDelimited", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.format+"/"+tc.in, func(t *testing.T) {
+			style, explode := collectionFormatStyle(tc.format, tc.in, slog.Default())
+			AssertEqual(t, tc.style, style)
+			AssertEqual(t, tc.explode, *explode)
+		})
+	}
+
+	if style, explode := collectionFormatStyle("tsv", "query", slog.Default()); style != "" || explode != nil {
+		t.Errorf("expected no style for tsv, got %q %v", style, explode)
+	}
+}
+
+func TestBuildParameter_TypeResolution(t *testing.T) {
+	g := NewTestGenerator()
+
+	ids := g.buildParameter(ParamAnnotation{Name: "ids", In: "query", Type: "[]int"})
+	AssertDeepEqual(t, &Schema{Type: "array", Items: &Schema{Type: "integer"}}, ids.Schema)
+	AssertEqual(t, "form", ids.Style)
+	AssertEqual(t, true, *ids.Explode)
+
+	header := g.buildParameter(ParamAnnotation{Name: "X-Ids", In: "header", Type: "[]string", CollectionFormat: "csv"})
+	AssertEqual(t, "simple", header.Style)
+
+	status := g.buildParameter(ParamAnnotation{Name: "status", In: "query", Type: "MyEnum"})
+	AssertEqual(t, "#/components/schemas/openapi.MyEnum", status.Schema.Ref)
+	AssertDeepEqual(t, []interface{}{"A", "B"}, g.schemaGen.GetSchemas()["openapi.MyEnum"].Enum)
+
+	since := g.buildParameter(ParamAnnotation{Name: "since", In: "query", Type: "time.Time", Example: "2024-01-01T00:00:00Z"})
+	AssertEqual(t, "string", since.Schema.Type)
+	AssertEqual(t, "date-time", since.Schema.Format)
+
+	// Attributes must not leak into the shared external type mapping
+	_ = g.buildParameter(ParamAnnotation{Name: "id", In: "path", Type: "uuid.UUID", Default: "x"})
+	AssertEqual(t, nil, g.schemaGen.knownTypes["uuid.UUID"].Default)
+}
+
+// --- Parameter struct fixtures ---
+type pageTestParams struct {
+	// Page number
+	Page  int `query:"page,omitempty" openapi:"minimum=1"`
+	Limit int `form:"limit,omitempty"`
+}
+
+type listTestQuery struct {
+	pageTestParams
+	Status   string   `query:"status" validate:"required"`
+	Tags     []string `schema:"tag"`
+	Active   *bool    `json:"active"`
+	Internal string   `query:"-"`
+	secret   string
+}
+
+type traceTestHeaders struct {
+	RequestID string `header:"X-Request-ID" validate:"required,uuid"`
+}
+
+type requiredTestFields struct {
+	Name    string  `json:"name"`
+	Nick    string  `json:"nick,omitempty"`
+	Email   *string `json:"email"`
+	Manager *string `json:"manager" validate:"required"`
+}
+
+// listTestItems lists items.
+// @Summary List items
+// @Param query listTestQuery
+// @Param header traceTestHeaders
+func listTestItems(w http.ResponseWriter, r *http.Request) {}
+
+func TestParseParamAnnotation_Struct(t *testing.T) {
+	param, err := parseParamAnnotation("@Param query ListUsersQuery", slog.Default())
+	AssertNoError(t, err)
+	AssertDeepEqual(t, &ParamAnnotation{In: "query", Type: "ListUsersQuery"}, param)
+
+	if _, err := parseParamAnnotation("@Param body ListUsersQuery", slog.Default()); err == nil {
+		t.Error("expected error for struct expansion in body")
+	}
+}
+
+func TestExpandStructParameters(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "parameters_test.go"))
+
+	params := g.expandStructParameters(ParamAnnotation{In: "query", Type: "listTestQuery"})
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+	AssertDeepEqual(t, []string{"page", "limit", "status", "tag", "active"}, names)
+
+	AssertEqual(t, "Page number", params[0].Description)
+	AssertEqual(t, 1.0, *params[0].Schema.Minimum)
+	AssertEqual(t, false, params[0].Required)
+	AssertEqual(t, true, params[2].Required)
+	AssertEqual(t, "array", params[3].Schema.Type)
+	AssertEqual(t, "form", params[3].Style)
+	AssertEqual(t, false, params[3].Required)
+	AssertEqual(t, "boolean", params[4].Schema.Type)
+	AssertEqual(t, false, params[4].Required)
+
+	headers := g.expandStructParameters(ParamAnnotation{In: "header", Type: "traceTestHeaders"})
+	AssertEqual(t, 1, len(headers))
+	AssertEqual(t, "X-Request-ID", headers[0].Name)
+	AssertEqual(t, "header", headers[0].In)
+	AssertEqual(t, true, headers[0].Required)
+	AssertEqual(t, "uuid", headers[0].Schema.Format)
+
+	AssertEqual(t, 0, len(g.expandStructParameters(ParamAnnotation{In: "query", Type: "missingTestQuery"})))
+}
+
+func TestGenerateSpec_StructParameters(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "parameters_test.go"))
+
+	r := chi.NewRouter()
+	r.Get("/items", listTestItems)
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+
+	op := spec.Paths["/items"].Get
+	AssertEqual(t, 6, len(op.Parameters))
+	AssertEqual(t, "X-Request-ID", op.Parameters[5].Name)
+}
+
+func TestBuildFormRequestBody_Multipart(t *testing.T) {
+	g := NewTestGenerator()
+	maxLength := 64
+	annotations := &Annotation{Parameters: []ParamAnnotation{
+		{Name: "avatar", In: "formData", Type: "file", Required: true, Description: "Avatar image", ContentType: "image/png,image/jpeg"},
+		{Name: "attachments", In: "formData", Type: "[]file", PartHeaders: []string{"X-Checksum"}},
+		{Name: "name", In: "formData", Type: "string", MaxLength: &maxLength},
+		{Name: "id", In: "path", Type: "int", Required: true},
+	}}
+
+	body := g.buildRequestBody(annotations)
+	AssertEqual(t, true, body.Required)
+	media, ok := body.Content["multipart/form-data"]
+	if !ok {
+		t.Fatalf("expected multipart/form-data content, got %v", body.Content)
+	}
+
+	schema := media.Schema
+	AssertEqual(t, 3, len(schema.Properties))
+	AssertDeepEqual(t, []string{"avatar"}, schema.Required)
+	AssertEqual(t, "binary", schema.Properties["avatar"].Format)
+	AssertEqual(t, "Avatar image", schema.Properties["avatar"].Description)
+	AssertEqual(t, "binary", schema.Properties["attachments"].Items.Format)
+	AssertEqual(t, 64, *schema.Properties["name"].MaxLength)
+
+	AssertEqual(t, "image/png,image/jpeg", media.Encoding["avatar"].ContentType)
+	AssertEqual(t, "application/octet-stream", media.Encoding["attachments"].ContentType)
+	if _, ok := media.Encoding["attachments"].Headers["X-Checksum"]; !ok {
+		t.Error("expected X-Checksum part header")
+	}
+	if _, ok := media.Encoding["name"]; ok {
+		t.Error("expected no encoding for plain text part")
+	}
+}
+
+func TestBuildFormRequestBody_URLEncoded(t *testing.T) {
+	g := NewTestGenerator()
+	body := g.buildRequestBody(&Annotation{Parameters: []ParamAnnotation{
+		{Name: "username", In: "formData", Type: "string", Required: true},
+		{Name: "scopes", In: "formData", Type: "[]string", CollectionFormat: "multi"},
+	}})
+
+	media, ok := body.Content["application/x-www-form-urlencoded"]
+	if !ok {
+		t.Fatalf("expected urlencoded content, got %v", body.Content)
+	}
+	AssertEqual(t, "form", media.Encoding["scopes"].Style)
+	AssertEqual(t, true, *media.Encoding["scopes"].Explode)
+
+	// @Accept overrides the inferred media type
+	body = g.buildRequestBody(&Annotation{
+		Accept:     []string{"multipart/form-data"},
+		Parameters: []ParamAnnotation{{Name: "username", In: "formData", Type: "string"}},
+	})
+	AssertEqual(t, false, body.Required)
+	if _, ok := body.Content["multipart/form-data"]; !ok {
+		t.Errorf("expected @Accept media type, got %v", body.Content)
+	}
+}
+
+func TestRequiredFields_SchemaAndParameters(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "parameters_test.go"))
+
+	var required []string
+	for _, p := range g.expandStructParameters(ParamAnnotation{In: "query", Type: "requiredTestFields"}) {
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	// Parameters are only required when validated as such; zero values mean "not sent"
+	AssertDeepEqual(t, []string{"manager"}, required)
+
+	g.schemaGen.GenerateSchema("requiredTestFields")
+	AssertDeepEqual(t, []string{"name", "manager"}, g.schemaGen.GetSchemas()["openapi.requiredTestFields"].Required)
+}
@@ -0,0 +1,121 @@
+package openapi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// wildcardParamName is the path parameter name published for chi's trailing "/*" catch-all.
+const wildcardParamName = "wildcard"
+
+// digitPattern matches chi regexes that only ever accept decimal digits, e.g. [0-9]+ or \d{4}.
+var digitPattern = regexp.MustCompile(`^\^?(\[0-9\]|\\d)(\+|\*|\{\d+(,\d*)?\})?\$?$`)
+
+// routeParam is a path parameter declared in a chi route pattern.
+type routeParam struct {
+	name     string
+	pattern  string // anchored regex from {name:regex}, empty when unconstrained
+	wildcard bool   // trailing "*" catch-all
+}
+
+// parseRoutePattern converts a chi route pattern into an OpenAPI path template and returns the
+// parameters it declares. Regex constraints are stripped from the template ({id:[0-9]+} becomes
+// {id}) and a trailing "*" becomes {wildcard}.
+func parseRoutePattern(route string) (string, []routeParam) {
+	var b strings.Builder
+	var params []routeParam
+
+	for i := 0; i < len(route); i++ {
+		switch c := route[i]; {
+		case c == '{':
+			end := closingBrace(route, i)
+			if end < 0 {
+				b.WriteString(route[i:])
+				return b.String(), params
+			}
+			name, pattern, _ := strings.Cut(route[i+1:end], ":")
+			b.WriteString("{" + name + "}")
+			params = append(params, routeParam{name: name, pattern: anchorPattern(pattern)})
+			i = end
+		case c == '*' && i == len(route)-1:
+			b.WriteString("{" + wildcardParamName + "}")
+			params = append(params, routeParam{name: wildcardParamName, wildcard: true})
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), params
+}
+
+// closingBrace returns the index of the brace closing the one at start, allowing nested regex
+// quantifiers such as {code:[a-z]{3}}. It returns -1 when the brace is never closed.
+func closingBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// anchorPattern anchors a route regex the same way chi does before matching a segment.
+func anchorPattern(pattern string) string {
+	if pattern == "" {
+		return ""
+	}
+	if !strings.HasPrefix(pattern, "^") {
+		pattern = "^" + pattern
+	}
+	if !strings.HasSuffix(pattern, "$") {
+		pattern += "$"
+	}
+	return pattern
+}
+
+// schema returns the parameter schema implied by the route: a non-negative integer for
+// digit-only regexes, otherwise a string carrying the regex as its pattern. OpenAPI only applies
+// pattern to strings, so integers do not carry it.
+func (p routeParam) schema() *Schema {
+	if p.pattern == "" {
+		return &Schema{Type: "string"}
+	}
+	if digitPattern.MatchString(p.pattern) {
+		minimum := 0.0
+		return &Schema{Type: "integer", Minimum: &minimum}
+	}
+	return &Schema{Type: "string", Pattern: p.pattern}
+}
+
+// mergePathParameter folds an annotated path parameter into the route-derived one of the same
+// name, keeping the route's regex on inline string schemas that do not declare their own.
+// Parameters that the route does not declare are appended.
+func mergePathParameter(params []Parameter, param Parameter) []Parameter {
+	for i, existing := range params {
+		if existing.In != "path" || existing.Name != param.Name {
+			continue
+		}
+		if param.Schema == nil {
+			param.Schema = existing.Schema
+		} else if param.Schema.Ref == "" && param.Schema.Type == "string" && param.Schema.Pattern == "" &&
+			existing.Schema != nil {
+			merged := *param.Schema
+			merged.Pattern = existing.Schema.Pattern
+			param.Schema = &merged
+		}
+		if param.Description == "" {
+			param.Description = existing.Description
+		}
+		param.Required = true
+		params[i] = param
+		return params
+	}
+	return append(params, param)
+}
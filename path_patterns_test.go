@@ -0,0 +1,139 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// getTestFile serves a file by path.
+// @Summary Get file
+// @Param id path int true "Bucket ID"
+// @Param wildcard path string true "File path inside the bucket"
+func getTestFile(w http.ResponseWriter, r *http.Request) {}
+
+func TestParseRoutePattern(t *testing.T) {
+	tests := []struct {
+		route  string
+		path   string
+		params []routeParam
+	}{
+		{"/users", "/users", nil},
+		{"/users/{id}", "/users/{id}", []routeParam{{name: "id"}}},
+		{"/users/{id:[0-9]+}", "/users/{id}", []routeParam{{name: "id", pattern: "^[0-9]+$"}}},
+		{"/codes/{code:^[a-z]{3}$}", "/codes/{code}", []routeParam{{name: "code", pattern: "^[a-z]{3}$"}}},
+		{"/files/{name}.{ext}", "/files/{name}.{ext}", []routeParam{{name: "name"}, {name: "ext"}}},
+		{"/static/*", "/static/{wildcard}", []routeParam{{name: "wildcard", wildcard: true}}},
+		{"/broken/{id", "/broken/{id", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			path, params := parseRoutePattern(tt.route)
+			AssertEqual(t, tt.path, path)
+			AssertEqual(t, len(tt.params), len(params))
+			for i := range tt.params {
+				AssertEqual(t, tt.params[i], params[i])
+			}
+		})
+	}
+}
+
+func TestRouteParamSchema(t *testing.T) {
+	tests := []struct {
+		pattern string
+		typ     string
+	}{
+		{"", "string"},
+		{"^[0-9]+$", "integer"},
+		{`^\d+$`, "integer"},
+		{`^\d{4}$`, "integer"},
+		{"^[0-9]{1,10}$", "integer"},
+		{"^[a-z-]+$", "string"},
+		{"^[0-9a-f]+$", "string"},
+	}
+
+	for _, tt := range tests {
+		schema := routeParam{name: "p", pattern: tt.pattern}.schema()
+		AssertEqual(t, tt.typ, schema.Type)
+		if tt.typ == "integer" {
+			// pattern only applies to strings
+			AssertEqual(t, "", schema.Pattern)
+			if schema.Minimum == nil || *schema.Minimum != 0 {
+				t.Errorf("expected minimum 0 for %q, got %v", tt.pattern, schema.Minimum)
+			}
+		} else {
+			AssertEqual(t, tt.pattern, schema.Pattern)
+		}
+	}
+}
+
+func TestGenerateSpec_RegexAndWildcardRoutes(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "path_patterns_test.go"))
+	r := chi.NewRouter()
+	r.Get("/buckets/{id:[0-9]+}/files/*", getTestFile)
+	r.Get("/slugs/{slug:[a-z-]+}", getTestFile)
+
+	spec := g.GenerateSpec(r, Config{Title: "Test", Version: "1.0.0"})
+
+	item := spec.Paths["/buckets/{id}/files/{wildcard}"]
+	if item == nil || item.Get == nil {
+		t.Fatalf("expected regex and wildcard route to be normalized, got paths %v", spec.Paths)
+	}
+	op := item.Get
+	AssertEqual(t, 2, len(op.Parameters))
+
+	id := op.Parameters[0]
+	AssertEqual(t, "id", id.Name)
+	AssertEqual(t, "integer", id.Schema.Type)
+	AssertEqual(t, "", id.Schema.Pattern)
+	AssertEqual(t, "Bucket ID", id.Description)
+
+	wildcard := op.Parameters[1]
+	AssertEqual(t, "wildcard", wildcard.Name)
+	AssertEqual(t, true, wildcard.Required)
+	AssertEqual(t, "File path inside the bucket", wildcard.Description)
+
+	slugOp := spec.Paths["/slugs/{slug}"].Get
+	var slug *Parameter
+	for i := range slugOp.Parameters {
+		if slugOp.Parameters[i].Name == "slug" {
+			slug = &slugOp.Parameters[i]
+		}
+	}
+	if slug == nil {
+		t.Fatalf("expected slug parameter, got %+v", slugOp.Parameters)
+	}
+	AssertEqual(t, "string", slug.Schema.Type)
+	AssertEqual(t, "^[a-z-]+$", slug.Schema.Pattern)
+}
+
+func TestMergePathParameter(t *testing.T) {
+	params := extractPathParameters("/users/{id:[0-9]+}")
+
+	params = mergePathParameter(params, Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "User ID",
+		Schema:      &Schema{Type: "integer", Format: "int64"},
+	})
+	AssertEqual(t, 1, len(params))
+	AssertEqual(t, "User ID", params[0].Description)
+	AssertEqual(t, "int64", params[0].Schema.Format)
+	AssertEqual(t, "", params[0].Schema.Pattern)
+	AssertEqual(t, true, params[0].Required)
+
+	// String schemas keep the route's regex, integers never get one
+	slugs := mergePathParameter(extractPathParameters("/posts/{slug:[a-z-]+}"), Parameter{
+		Name: "slug", In: "path", Schema: &Schema{Type: "string"},
+	})
+	AssertEqual(t, "^[a-z-]+$", slugs[0].Schema.Pattern)
+	codes := mergePathParameter(extractPathParameters("/codes/{code:[0-9a-f]+}"), Parameter{
+		Name: "code", In: "path", Schema: &Schema{Type: "integer"},
+	})
+	AssertEqual(t, "", codes[0].Schema.Pattern)
+
+	params = mergePathParameter(params, Parameter{Name: "extra", In: "path", Schema: &Schema{Type: "string"}})
+	AssertEqual(t, 2, len(params))
+}
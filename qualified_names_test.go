@@ -53,8 +53,8 @@ func TestQualifiedNaming_External(t *testing.T) {
 	_, inSchemas := schemas["time.Time"]
 
 	// Check external known types if not in regular schemas
-	if !inSchemas && gen.typeIndex != nil {
-		if extSchema, inExt := gen.typeIndex.externalKnownTypes["time.Time"]; inExt {
+	if !inSchemas {
+		if extSchema, inExt := gen.knownTypes["time.Time"]; inExt {
 			if extSchema.Type != "string" || extSchema.Format != "date-time" {
 				t.Error("time.Time should have proper external type mapping")
 			}
@@ -108,7 +108,6 @@ func TestQualifiedNaming_Nested(t *testing.T) {
 
 // TestTypeIndexQualifiedLookup tests the new TypeIndex qualified lookup methods
 func TestTypeIndexQualifiedLookup(t *testing.T) {
-	resetTypeIndexForTesting()
 	idx := BuildTypeIndex()
 
 	t.Run("LookupQualifiedType works", func(t *testing.T) {
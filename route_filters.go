@@ -0,0 +1,136 @@
+package openapi
+
+import (
+	"log/slog"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RouteRule matches routes by path, method or an arbitrary predicate.
+// Every criterion that is set must match: PathPrefixes, Globs and Patterns are alternatives for
+// the path (any one of them may match), Methods restricts the HTTP method, and Match is called
+// last. A rule with no criteria matches every route.
+//
+// Paths are chi patterns as registered, e.g. "/users/{id}" or "/debug/pprof/*".
+type RouteRule struct {
+	PathPrefixes []string             // whole-segment prefixes: "/admin" matches "/admin/users" but not "/administrators"
+	Globs        []string             // path.Match patterns, where "*" matches within a single segment
+	Patterns     []*regexp.Regexp     // regular expressions matched against the pattern
+	Methods      []string             // HTTP methods, case-insensitive
+	Match        func(RouteInfo) bool // custom predicate
+}
+
+// DocumentationRoutes matches routes that serve the documentation itself: a path segment named
+// swagger or openapi, or a spec file such as openapi.json. Resources that merely contain those
+// words, like /openapi-keys, do not match.
+func DocumentationRoutes() RouteRule {
+	return RouteRule{Match: func(ri RouteInfo) bool { return isInternalRoute(ri.Pattern) }}
+}
+
+// DefaultExcludeRoutes returns the exclude rules used when Config.ExcludeRoutes is nil.
+func DefaultExcludeRoutes() []RouteRule {
+	return []RouteRule{DocumentationRoutes()}
+}
+
+// excludeRules returns the configured exclude rules, or the defaults when none are set.
+func excludeRules(cfg Config) []RouteRule {
+	if cfg.ExcludeRoutes == nil {
+		return DefaultExcludeRoutes()
+	}
+	return cfg.ExcludeRoutes
+}
+
+// matches reports whether the route satisfies every criterion set on the rule.
+func (r RouteRule) matches(ri RouteInfo) bool {
+	if len(r.PathPrefixes) > 0 || len(r.Globs) > 0 || len(r.Patterns) > 0 {
+		if !r.matchesPath(ri.Pattern) {
+			return false
+		}
+	}
+	if len(r.Methods) > 0 && !containsMethod(r.Methods, ri.Method) {
+		return false
+	}
+	if r.Match != nil && !r.Match(ri) {
+		return false
+	}
+	return true
+}
+
+// matchesPath reports whether the pattern matches any of the rule's prefixes, globs or regexes.
+func (r RouteRule) matchesPath(pattern string) bool {
+	for _, prefix := range r.PathPrefixes {
+		if hasPathPrefix(pattern, prefix) {
+			return true
+		}
+	}
+	for _, glob := range r.Globs {
+		if ok, _ := path.Match(glob, pattern); ok {
+			return true
+		}
+	}
+	for _, re := range r.Patterns {
+		if re != nil && re.MatchString(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPathPrefix reports whether prefix is a whole-segment prefix of the route pattern.
+func hasPathPrefix(pattern, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return true
+	}
+	return pattern == prefix || strings.HasPrefix(pattern, prefix+"/")
+}
+
+// containsMethod reports whether methods contains method, ignoring case.
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRoutes keeps routes matching at least one include rule (all routes when there are none)
+// and no exclude rule. Invalid globs are reported once and never match.
+func filterRoutes(routes []RouteInfo, include, exclude []RouteRule, logger *slog.Logger) []RouteInfo {
+	if len(include) == 0 && len(exclude) == 0 {
+		return routes
+	}
+	for _, rule := range append(include[:len(include):len(include)], exclude...) {
+		for _, glob := range rule.Globs {
+			if _, err := path.Match(glob, ""); err != nil {
+				logger.Warn("[openapi] filterRoutes: invalid glob", "glob", glob, "error", err)
+			}
+		}
+	}
+
+	var filtered []RouteInfo
+	for _, ri := range routes {
+		if len(include) > 0 && !anyRuleMatches(include, ri) {
+			logger.Debug("[openapi] filterRoutes: not included", "method", ri.Method, "pattern", ri.Pattern)
+			continue
+		}
+		if anyRuleMatches(exclude, ri) {
+			logger.Debug("[openapi] filterRoutes: excluded", "method", ri.Method, "pattern", ri.Pattern)
+			continue
+		}
+		filtered = append(filtered, ri)
+	}
+	return filtered
+}
+
+// anyRuleMatches reports whether any rule matches the route.
+func anyRuleMatches(rules []RouteRule, ri RouteInfo) bool {
+	for _, rule := range rules {
+		if rule.matches(ri) {
+			return true
+		}
+	}
+	return false
+}
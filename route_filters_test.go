@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// getTestDebugInfo is only for operators.
+// @Summary Debug info
+// @Hidden
+func getTestDebugInfo(w http.ResponseWriter, r *http.Request) {}
+
+// listTestWidgets is public.
+// @Summary List widgets
+func listTestWidgets(w http.ResponseWriter, r *http.Request) {}
+
+func TestRouteRule_Matches(t *testing.T) {
+	get := RouteInfo{Method: "GET", Pattern: "/admin/users/{id}"}
+	del := RouteInfo{Method: "DELETE", Pattern: "/admin/users/{id}"}
+	other := RouteInfo{Method: "GET", Pattern: "/administrators"}
+
+	tests := []struct {
+		name string
+		rule RouteRule
+		want []bool // get, del, other
+	}{
+		{"empty rule", RouteRule{}, []bool{true, true, true}},
+		{"prefix", RouteRule{PathPrefixes: []string{"/admin/"}}, []bool{true, true, false}},
+		{"glob", RouteRule{Globs: []string{"/admin/*/{id}"}}, []bool{true, true, false}},
+		{"regex", RouteRule{Patterns: []*regexp.Regexp{regexp.MustCompile(`^/admin`)}}, []bool{true, true, true}},
+		{"method", RouteRule{Methods: []string{"delete"}}, []bool{false, true, false}},
+		{"prefix and method", RouteRule{PathPrefixes: []string{"/admin"}, Methods: []string{"GET"}}, []bool{true, false, false}},
+		{"predicate", RouteRule{Match: func(ri RouteInfo) bool { return strings.HasSuffix(ri.Pattern, "s") }}, []bool{false, false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, ri := range []RouteInfo{get, del, other} {
+				AssertEqual(t, tt.want[i], tt.rule.matches(ri))
+			}
+		})
+	}
+}
+
+func TestFilterRoutes(t *testing.T) {
+	routes := []RouteInfo{
+		{Method: "GET", Pattern: "/api/widgets"},
+		{Method: "DELETE", Pattern: "/api/widgets/{id}"},
+		{Method: "GET", Pattern: "/health"},
+		{Method: "GET", Pattern: "/debug/pprof/*"},
+	}
+
+	filtered := filterRoutes(routes,
+		[]RouteRule{{PathPrefixes: []string{"/api"}}, {PathPrefixes: []string{"/debug"}}},
+		[]RouteRule{{PathPrefixes: []string{"/debug"}}, {Methods: []string{"DELETE"}}},
+		slog.Default(),
+	)
+	AssertEqual(t, 1, len(filtered))
+	AssertEqual(t, "/api/widgets", filtered[0].Pattern)
+
+	AssertEqual(t, len(routes), len(filterRoutes(routes, nil, nil, slog.Default())))
+}
+
+func TestGenerateSpec_RouteFiltersAndHidden(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "route_filters_test.go"))
+	r := chi.NewRouter()
+	r.Get("/widgets", listTestWidgets)
+	r.Delete("/widgets", listTestWidgets)
+	r.Get("/debug/info", getTestDebugInfo)
+	r.Get("/health", listTestWidgets)
+
+	spec := g.GenerateSpec(r, Config{
+		Title:         "Test",
+		Version:       "1.0.0",
+		ExcludeRoutes: []RouteRule{{Globs: []string{"/health"}}, {Methods: []string{"DELETE"}}},
+	})
+
+	if item := spec.Paths["/widgets"]; item == nil || item.Get == nil {
+		t.Fatalf("expected GET /widgets, got %v", spec.Paths)
+	}
+	if spec.Paths["/widgets"].Delete != nil {
+		t.Error("expected DELETE /widgets to be excluded")
+	}
+	if _, ok := spec.Paths["/health"]; ok {
+		t.Error("expected /health to be excluded")
+	}
+	if _, ok := spec.Paths["/debug/info"]; ok {
+		t.Error("expected @Hidden route to be omitted")
+	}
+}
+
+func TestGenerateSpec_DefaultExcludeRoutes(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "route_filters_test.go"))
+	r := chi.NewRouter()
+	r.Get("/v1/openapi/{id}", listTestWidgets)
+	r.Get("/openapi.json", listTestWidgets)
+	r.Get("/health", listTestWidgets)
+
+	documented := func(cfg Config) []string {
+		cfg.Title, cfg.Version = "Test", "1.0.0"
+		spec := g.GenerateSpec(r, cfg)
+		var paths []string
+		for _, p := range []string{"/v1/openapi/{id}", "/openapi.json", "/health"} {
+			if _, ok := spec.Paths[p]; ok {
+				paths = append(paths, p)
+			}
+		}
+		return paths
+	}
+
+	// Documentation endpoints are excluded while ExcludeRoutes is nil
+	AssertDeepEqual(t, []string{"/health"}, documented(Config{}))
+	// An empty slice disables the defaults
+	AssertDeepEqual(t, []string{"/v1/openapi/{id}", "/openapi.json", "/health"}, documented(Config{ExcludeRoutes: []RouteRule{}}))
+	// Custom rules replace the defaults unless appended to them
+	AssertDeepEqual(t, []string{"/v1/openapi/{id}", "/openapi.json"},
+		documented(Config{ExcludeRoutes: []RouteRule{{Globs: []string{"/health"}}}}))
+	AssertDeepEqual(t, []string(nil),
+		documented(Config{ExcludeRoutes: append(DefaultExcludeRoutes(), RouteRule{Globs: []string{"/health"}})}))
+}
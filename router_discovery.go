@@ -2,7 +2,9 @@ package openapi
 
 import (
 	"fmt"
+	"log/slog"
 	"net/http"
+	"path"
 	"reflect"
 	"runtime"
 	"strings"
@@ -12,11 +14,14 @@ import (
 
 // RouteInfo holds metadata about each registered route
 // including HTTP method, path pattern, handler name, and function.
+// Handler keeps the handler exactly as registered so adapters can be unwrapped later;
+// HandlerName names the function that documents it (see HandlerUnwrapper).
 type RouteInfo struct {
 	Method      string
 	Pattern     string
 	HandlerName string
 	HandlerFunc http.HandlerFunc
+	Handler     http.Handler
 	Middlewares []func(http.Handler) http.Handler
 }
 
@@ -55,12 +60,16 @@ func InspectRoutes(r chi.Router) ([]RouteInfo, error) {
 			// wrap other handlers
 			hf = h.ServeHTTP
 		}
-		name := runtime.FuncForPC(reflect.ValueOf(hf).Pointer()).Name()
+		var name string
+		if fn := resolveHandlerFunc(handler); fn != nil {
+			name = runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+		}
 		routes = append(routes, RouteInfo{
 			Method:      method,
 			Pattern:     route,
 			HandlerName: name,
 			HandlerFunc: hf,
+			Handler:     handler,
 			Middlewares: middlewares,
 		})
 		return nil
@@ -76,22 +85,26 @@ func InspectRoutes(r chi.Router) ([]RouteInfo, error) {
 	return routes, nil
 }
 
-// DiscoverRoutes returns only non-internal routes for OpenAPI spec assembly.
-// This function filters out routes that are part of the OpenAPI tooling itself
-// (such as /swagger and /openapi endpoints) to avoid circular references in the specification.
+// DiscoverRoutes returns the routes that DefaultExcludeRoutes keeps, leaving out the endpoints
+// that serve the documentation itself (such as /swagger and /openapi).
+// GenerateSpec applies Config.ExcludeRoutes instead, which defaults to the same rules.
 func DiscoverRoutes(r chi.Router) ([]RouteInfo, error) {
-	// Retrieve all routes via InspectRoutes
 	infos, err := InspectRoutes(r)
 	if err != nil {
 		return nil, err
 	}
-	var filtered []RouteInfo
-	for _, ri := range infos {
-		// Skip OpenAPI internals
-		if strings.Contains(ri.Pattern, "/swagger") || strings.Contains(ri.Pattern, "/openapi") {
-			continue
+	return filterRoutes(infos, nil, DefaultExcludeRoutes(), slog.Default()), nil
+}
+
+// isInternalRoute reports whether a pattern serves the documentation itself: a path segment named
+// swagger or openapi, or a spec file such as openapi.json. Resources that merely contain those
+// words, like /openapi-keys, are kept.
+func isInternalRoute(pattern string) bool {
+	for _, segment := range strings.Split(pattern, "/") {
+		name := strings.TrimSuffix(segment, path.Ext(segment))
+		if name == "swagger" || name == "openapi" {
+			return true
 		}
-		filtered = append(filtered, ri)
 	}
-	return filtered, nil
+	return false
 }
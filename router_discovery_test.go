@@ -82,6 +82,27 @@ func TestDiscoverRoutes_FiltersInternal(t *testing.T) {
 	}
 }
 
+// TestDiscoverRoutes_KeepsLookalikeResources ensures only whole segments mark internal routes.
+func TestDiscoverRoutes_KeepsLookalikeResources(t *testing.T) {
+	r := chi.NewRouter()
+	stub := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	r.Get("/api/v1/openapi-keys", stub)
+	r.Get("/api/v1/openapi.json", stub)
+	r.Get("/docs/swagger.yaml", stub)
+	r.Get("/swaggering", stub)
+	routes, err := DiscoverRoutes(r)
+	if err != nil {
+		t.Fatalf("DiscoverRoutes returned error: %v", err)
+	}
+	patterns := make(map[string]bool)
+	for _, ri := range routes {
+		patterns[ri.Pattern] = true
+	}
+	if len(patterns) != 2 || !patterns["/api/v1/openapi-keys"] || !patterns["/swaggering"] {
+		t.Errorf("Expected /api/v1/openapi-keys and /swaggering, got %v", patterns)
+	}
+}
+
 // TestInspectRoutes_Middleware checks that middlewares are captured in RouteInfo.
 func TestInspectRoutes_Middleware(t *testing.T) {
 	r := chi.NewRouter()
@@ -1,7 +1,6 @@
 package openapi
 
 import (
-	"fmt"
 	"go/ast"
 	"log/slog"
 	"strings"
@@ -10,38 +9,75 @@ import (
 
 // SchemaGenerator handles dynamic schema generation from Go types
 // If a TypeIndex is provided, it will be used for fast lookup.
+// Generated schemas and known types belong to the generator and are guarded by its mutex;
+// the TypeIndex is only read.
 type SchemaGenerator struct {
-	schemas   map[string]*Schema
-	typeIndex *TypeIndex
-	mutex     sync.Mutex
+	schemas    map[string]*Schema
+	knownTypes map[string]*Schema // schemas of external types, by qualified name
+	typeIndex  *TypeIndex
+	audience   string // when set, struct fields restricted to other audiences are omitted
+	logger     *slog.Logger
+
+	naming SchemaNaming // component schema names; nil keeps qualified names
+
+	mutex sync.Mutex
 }
 
-// NewSchemaGenerator creates a new schema generator. Optionally accepts a TypeIndex.
+// NewSchemaGenerator creates a new schema generator. Optionally accepts a TypeIndex;
+// without one, the current project is indexed.
 func NewSchemaGenerator(opts ...*TypeIndex) *SchemaGenerator {
 	slog.Debug("[openapi] NewSchemaGenerator: initializing", "opts_len", len(opts))
 	var idx *TypeIndex
 	if len(opts) > 0 && opts[0] != nil {
 		idx = opts[0]
 	} else {
-		// Use global typeIndex if no specific index provided
-		ensureTypeIndex() // Ensure typeIndex is initialized
-		idx = typeIndex
+		idx = BuildTypeIndex()
 	}
 
 	return &SchemaGenerator{
-		schemas:   make(map[string]*Schema),
-		typeIndex: idx,
+		schemas:    make(map[string]*Schema),
+		knownTypes: newKnownTypes(),
+		typeIndex:  idx,
+	}
+}
+
+// log returns the generator's logger.
+func (sg *SchemaGenerator) log() *slog.Logger {
+	if sg.logger != nil {
+		return sg.logger
+	}
+	return slog.Default()
+}
+
+// AddExternalKnownType registers the schema used for an external type, e.g. "decimal.Decimal".
+func (sg *SchemaGenerator) AddExternalKnownType(name string, schema *Schema) {
+	sg.mutex.Lock()
+	defer sg.mutex.Unlock()
+	sg.knownTypes[name] = schema
+	sg.log().Debug("[openapi] AddExternalKnownType: added external known type", "name", name)
+}
+
+// knownType returns a copy of the schema registered for an external type, so callers may
+// adjust it (e.g. with struct tags) without changing the registry.
+func (sg *SchemaGenerator) knownType(name string) (*Schema, bool) {
+	sg.mutex.Lock()
+	defer sg.mutex.Unlock()
+	schema, ok := sg.knownTypes[name]
+	if !ok {
+		return nil, false
 	}
+	copied := *schema
+	return &copied, true
 }
 
 // GenerateSchema creates a JSON schema for the given type name.
 // All types are stored using qualified names (e.g., "order.CreateReq", "sqlc.User").
 func (sg *SchemaGenerator) GenerateSchema(typeName string) *Schema {
-	slog.Debug("[openapi] GenerateSchema: called", "typeName", typeName)
+	sg.log().Debug("[openapi] GenerateSchema: called", "typeName", typeName)
 
 	// 1) Fast-path simple/empty types
 	if typeName == "" || typeName == "<autogenerated>" {
-		slog.Debug("[openapi] GenerateSchema: empty typeName, returning object schema")
+		sg.log().Debug("[openapi] GenerateSchema: empty typeName, returning object schema")
 		return &Schema{Type: "object"}
 	}
 
@@ -50,16 +86,19 @@ func (sg *SchemaGenerator) GenerateSchema(typeName string) *Schema {
 		return sg.generateBasicTypeSchema(typeName)
 	}
 
+	// Generic instantiations and field overrides compose their own schemas
+	if schema, ok := sg.generateCompositeSchema(typeName); ok {
+		return schema
+	}
+
 	// 3) Normalize the type name to use qualified names
 	qualifiedName := sg.getQualifiedTypeName(typeName)
-	slog.Debug("[openapi] GenerateSchema: type name conversion", "typeName", typeName, "qualifiedName", qualifiedName)
+	sg.log().Debug("[openapi] GenerateSchema: type name conversion", "typeName", typeName, "qualifiedName", qualifiedName)
 
 	// 4) Check external known types first
-	if sg.typeIndex != nil {
-		if schema, ok := sg.typeIndex.externalKnownTypes[qualifiedName]; ok {
-			slog.Debug("[openapi] GenerateSchema: using externalKnownTypes", "qualifiedName", qualifiedName)
-			return schema
-		}
+	if schema, ok := sg.knownType(qualifiedName); ok {
+		sg.log().Debug("[openapi] GenerateSchema: using known type", "qualifiedName", qualifiedName)
+		return schema
 	}
 
 	// 5) Check if schema already exists (avoid duplicate work)
@@ -68,10 +107,10 @@ func (sg *SchemaGenerator) GenerateSchema(typeName string) *Schema {
 		sg.mutex.Unlock()
 		if existingSchema == nil {
 			// Currently being processed, return reference
-			return &Schema{Ref: fmt.Sprintf("#/components/schemas/%s", qualifiedName)}
+			return sg.schemaRef(qualifiedName)
 		}
-		slog.Debug("[openapi] GenerateSchema: schema already exists", "qualifiedName", qualifiedName)
-		return &Schema{Ref: fmt.Sprintf("#/components/schemas/%s", qualifiedName)}
+		sg.log().Debug("[openapi] GenerateSchema: schema already exists", "qualifiedName", qualifiedName)
+		return sg.schemaRef(qualifiedName)
 	}
 
 	// 6) Reserve placeholder to prevent infinite recursion
@@ -80,11 +119,11 @@ func (sg *SchemaGenerator) GenerateSchema(typeName string) *Schema {
 
 	// 7) Check if it's an enum type
 	if enumSchema := sg.handleEnumType(qualifiedName); enumSchema != nil {
-		slog.Debug("[openapi] GenerateSchema: detected enum type", "qualifiedName", qualifiedName)
+		sg.log().Debug("[openapi] GenerateSchema: detected enum type", "qualifiedName", qualifiedName)
 		sg.mutex.Lock()
 		sg.schemas[qualifiedName] = enumSchema
 		sg.mutex.Unlock()
-		return &Schema{Ref: fmt.Sprintf("#/components/schemas/%s", qualifiedName)}
+		return sg.schemaRef(qualifiedName)
 	}
 
 	// 8) Generate the actual schema
@@ -93,7 +132,7 @@ func (sg *SchemaGenerator) GenerateSchema(typeName string) *Schema {
 		// Try qualified lookup first
 		if ts := sg.typeIndex.LookupQualifiedType(qualifiedName); ts != nil {
 			if structType, ok := ts.Type.(*ast.StructType); ok {
-				slog.Debug("[openapi] GenerateSchema: found struct in TypeIndex", "qualifiedName", qualifiedName)
+				sg.log().Debug("[openapi] GenerateSchema: found struct in TypeIndex", "qualifiedName", qualifiedName)
 				built = sg.convertStructToSchema(structType)
 			}
 		}
@@ -101,7 +140,7 @@ func (sg *SchemaGenerator) GenerateSchema(typeName string) *Schema {
 
 	// 9) Fallback for unknown types
 	if built == nil {
-		slog.Debug(
+		sg.log().Debug(
 			"[openapi] GenerateSchema: TypeIndex lookup failed, using basic mapping",
 			"qualifiedName",
 			qualifiedName,
@@ -115,17 +154,12 @@ func (sg *SchemaGenerator) GenerateSchema(typeName string) *Schema {
 
 	// 10) Store the built schema
 	sg.mutex.Lock()
-	slog.Debug("[openapi] GenerateSchema: storing schema", "qualifiedName", qualifiedName, "originalTypeName", typeName)
+	sg.log().Debug("[openapi] GenerateSchema: storing schema", "qualifiedName", qualifiedName, "originalTypeName", typeName)
 	sg.schemas[qualifiedName] = built
-	if sg.typeIndex != nil && sg.typeIndex.externalKnownTypes != nil {
-		sg.typeIndex.externalKnownTypes[qualifiedName] = &Schema{
-			Ref: fmt.Sprintf("#/components/schemas/%s", qualifiedName),
-		}
-	}
 	sg.mutex.Unlock()
 
 	// 11) Always return a reference
-	return &Schema{Ref: fmt.Sprintf("#/components/schemas/%s", qualifiedName)}
+	return sg.schemaRef(qualifiedName)
 }
 
 // getQualifiedTypeName returns the qualified type name for schema keys.
@@ -133,24 +167,40 @@ func (sg *SchemaGenerator) GenerateSchema(typeName string) *Schema {
 func (sg *SchemaGenerator) getQualifiedTypeName(typeName string) string {
 	// Already qualified
 	if strings.Contains(typeName, ".") {
-		slog.Debug("[openapi] getQualifiedTypeName: already qualified", "typeName", typeName)
+		sg.log().Debug("[openapi] getQualifiedTypeName: already qualified", "typeName", typeName)
 		return typeName
 	}
 	if sg.typeIndex != nil {
 		qualified := sg.typeIndex.GetQualifiedTypeName(typeName)
-		slog.Debug("[openapi] getQualifiedTypeName: converted", "typeName", typeName, "qualifiedName", qualified)
+		sg.log().Debug("[openapi] getQualifiedTypeName: converted", "typeName", typeName, "qualifiedName", qualified)
 		return qualified
 	}
-	slog.Debug("[openapi] getQualifiedTypeName: no typeIndex, using original", "typeName", typeName)
+	sg.log().Debug("[openapi] getQualifiedTypeName: no typeIndex, using original", "typeName", typeName)
 	return typeName
 }
 
-// GetSchemas returns all generated schemas.
+// setAudience selects the audience whose struct fields are documented.
+// Schemas generated for a different audience are discarded.
+func (sg *SchemaGenerator) setAudience(audience string) {
+	sg.mutex.Lock()
+	defer sg.mutex.Unlock()
+	if sg.audience != audience {
+		sg.audience = audience
+		sg.schemas = make(map[string]*Schema)
+	}
+}
+
+// GetSchemas returns deep copies of all generated schemas, so callers may modify them without
+// affecting later generations.
 func (sg *SchemaGenerator) GetSchemas() map[string]Schema {
-	slog.Debug("[openapi] GetSchemas: returning all generated schemas", "count", len(sg.schemas))
+	sg.mutex.Lock()
+	defer sg.mutex.Unlock()
+	sg.log().Debug("[openapi] GetSchemas: returning all generated schemas", "count", len(sg.schemas))
 	result := make(map[string]Schema, len(sg.schemas))
 	for name, schema := range sg.schemas {
-		result[name] = *schema
+		if schema != nil {
+			result[name] = *cloneSchema(schema)
+		}
 	}
 	return result
 }
@@ -5,13 +5,12 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
-	"log/slog"
 	"strings"
 )
 
 // handleEnumType checks if a qualified Go type is a string-based enum and generates a schema with enum values.
 func (sg *SchemaGenerator) handleEnumType(qualifiedName string) *Schema {
-	slog.Debug("[openapi] handleEnumType: checking enum type", "qualifiedName", qualifiedName)
+	sg.log().Debug("[openapi] handleEnumType: checking enum type", "qualifiedName", qualifiedName)
 	if sg.typeIndex == nil {
 		return nil
 	}
@@ -43,7 +42,7 @@ func (sg *SchemaGenerator) handleEnumType(qualifiedName string) *Schema {
 
 // extractEnumValues finds constant string values for a given type in AST files.
 func (sg *SchemaGenerator) extractEnumValues(packageName, typeName string) []interface{} {
-	slog.Debug("[openapi] extractEnumValues: extracting values", "pkg", packageName, "type", typeName)
+	sg.log().Debug("[openapi] extractEnumValues: extracting values", "pkg", packageName, "type", typeName)
 	if sg.typeIndex == nil {
 		return nil
 	}
@@ -0,0 +1,220 @@
+// Package openapi provides schema generation for generic instantiations and field overrides.
+package openapi
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"strings"
+)
+
+// generateCompositeSchema handles annotation types that are not plain type names:
+// generic instantiations such as "Envelope[User]" and swaggo-style field overrides such as
+// "Envelope{data=User}". It reports false for plain type names.
+func (sg *SchemaGenerator) generateCompositeSchema(typeName string) (*Schema, bool) {
+	if strings.HasPrefix(typeName, "[]") {
+		return nil, false
+	}
+	if brace := topLevelIndex(typeName, '{'); brace > 0 && strings.HasSuffix(typeName, "}") {
+		return sg.generateOverrideSchema(typeName[:brace], typeName[brace+1:len(typeName)-1]), true
+	}
+	if bracket := strings.Index(typeName, "["); bracket > 0 && strings.HasSuffix(typeName, "]") {
+		return sg.generateGenericSchema(typeName[:bracket], splitTypeList(typeName[bracket+1:len(typeName)-1])), true
+	}
+	return nil, false
+}
+
+// generateGenericSchema emits a concrete component schema for a generic instantiation,
+// e.g. "Envelope[User]" becomes "pkg.Envelope_User", by substituting the type arguments
+// into the indexed type declaration. Arguments from other packages keep their qualifier
+// ("pkg.Envelope_billing.User"), so same-named types never share an instance schema.
+func (sg *SchemaGenerator) generateGenericSchema(base string, args []string) *Schema {
+	sg.log().Debug("[openapi] generateGenericSchema: called", "base", base, "args", args)
+	qualifiedBase := sg.getQualifiedTypeName(base)
+	ts := sg.typeIndex.LookupQualifiedType(qualifiedBase)
+	if ts == nil || ts.TypeParams == nil || ts.TypeParams.NumFields() != len(args) {
+		sg.log().Warn("[openapi] generateGenericSchema: not a matching generic type", "base", qualifiedBase, "args", args)
+		return sg.GenerateSchema(base)
+	}
+
+	// Bind type parameters to the parsed type arguments
+	subst := make(map[string]ast.Expr, len(args))
+	i := 0
+	for _, field := range ts.TypeParams.List {
+		for _, name := range field.Names {
+			arg, err := parser.ParseExpr(args[i])
+			if err != nil {
+				sg.log().Warn("[openapi] generateGenericSchema: invalid type argument", "arg", args[i], "error", err)
+				return sg.GenerateSchema(base)
+			}
+			subst[name.Name] = arg
+			i++
+		}
+	}
+
+	pkg, _, _ := strings.Cut(qualifiedBase, ".")
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = sg.instanceName(arg, pkg)
+	}
+	instName := qualifiedBase + "_" + strings.Join(parts, "_")
+	ref := sg.schemaRef(instName)
+
+	sg.mutex.Lock()
+	if _, exists := sg.schemas[instName]; exists {
+		sg.mutex.Unlock()
+		return ref
+	}
+	sg.schemas[instName] = nil
+	sg.mutex.Unlock()
+
+	var built *Schema
+	if structType, ok := substituteTypeParams(ts.Type, subst).(*ast.StructType); ok {
+		built = sg.convertStructToSchema(structType)
+	} else {
+		built = sg.convertFieldType(substituteTypeParams(ts.Type, subst))
+	}
+
+	sg.mutex.Lock()
+	sg.schemas[instName] = built
+	sg.mutex.Unlock()
+	return ref
+}
+
+// generateOverrideSchema composes the base type with overridden properties,
+// e.g. "Envelope{data=User,meta=Meta}" becomes allOf[Envelope, {data: User, meta: Meta}].
+func (sg *SchemaGenerator) generateOverrideSchema(base, overrides string) *Schema {
+	sg.log().Debug("[openapi] generateOverrideSchema: called", "base", base, "overrides", overrides)
+	properties := make(map[string]*Schema)
+	for _, override := range splitTypeList(overrides) {
+		name, value, ok := strings.Cut(override, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			sg.log().Warn("[openapi] generateOverrideSchema: invalid field override", "override", override)
+			continue
+		}
+		properties[strings.TrimSpace(name)] = sg.overrideValueSchema(strings.TrimSpace(value))
+	}
+
+	return &Schema{AllOf: []*Schema{
+		sg.GenerateSchema(base),
+		{Type: "object", Properties: properties},
+	}}
+}
+
+// overrideValueSchema resolves the type of an overridden field: slices become arrays,
+// basic types are inlined and everything else goes through GenerateSchema.
+func (sg *SchemaGenerator) overrideValueSchema(value string) *Schema {
+	if strings.HasPrefix(value, "[]") {
+		return &Schema{Type: "array", Items: sg.overrideValueSchema(strings.TrimPrefix(value, "[]"))}
+	}
+	value = strings.TrimPrefix(value, "*")
+	if basic := mapGoTypeToOpenAPI(value); basic != "object" {
+		return &Schema{Type: basic}
+	}
+	return sg.GenerateSchema(value)
+}
+
+// substituteTypeParams returns a copy of expr with type parameter identifiers replaced.
+// Only the expression kinds that can appear in type declarations are rewritten.
+func substituteTypeParams(expr ast.Expr, subst map[string]ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if replacement, ok := subst[t.Name]; ok {
+			return replacement
+		}
+		return t
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: substituteTypeParams(t.X, subst)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: t.Len, Elt: substituteTypeParams(t.Elt, subst)}
+	case *ast.MapType:
+		return &ast.MapType{Key: substituteTypeParams(t.Key, subst), Value: substituteTypeParams(t.Value, subst)}
+	case *ast.IndexExpr:
+		return &ast.IndexExpr{X: t.X, Index: substituteTypeParams(t.Index, subst)}
+	case *ast.IndexListExpr:
+		indices := make([]ast.Expr, len(t.Indices))
+		for i, index := range t.Indices {
+			indices[i] = substituteTypeParams(index, subst)
+		}
+		return &ast.IndexListExpr{X: t.X, Indices: indices}
+	case *ast.StructType:
+		fields := make([]*ast.Field, len(t.Fields.List))
+		for i, field := range t.Fields.List {
+			copied := *field
+			copied.Type = substituteTypeParams(field.Type, subst)
+			fields[i] = &copied
+		}
+		return &ast.StructType{Fields: &ast.FieldList{List: fields}}
+	}
+	return expr
+}
+
+// genericTypeName renders a generic type expression from a struct field, e.g. "Page[User]".
+func genericTypeName(expr ast.Expr) string {
+	return types.ExprString(expr)
+}
+
+// instanceName turns a type argument into a schema name segment. Named types declared in pkg,
+// the generic type's package, drop their qualifier and all others keep it:
+// "User" -> "User", "billing.User" -> "billing.User", "[]User" -> "UserList", "Page[User]" -> "Page_User".
+func (sg *SchemaGenerator) instanceName(arg, pkg string) string {
+	arg = strings.TrimPrefix(strings.TrimSpace(arg), "*")
+	if strings.HasPrefix(arg, "[]") {
+		return sg.instanceName(strings.TrimPrefix(arg, "[]"), pkg) + "List"
+	}
+	if bracket := strings.Index(arg, "["); bracket > 0 && strings.HasSuffix(arg, "]") {
+		args := splitTypeList(arg[bracket+1 : len(arg)-1])
+		parts := []string{sg.instanceName(arg[:bracket], pkg)}
+		for _, a := range args {
+			parts = append(parts, sg.instanceName(a, pkg))
+		}
+		return strings.Join(parts, "_")
+	}
+	if isBasicType(arg) {
+		return arg
+	}
+	qualified := sg.getQualifiedTypeName(arg)
+	if argPkg, name, ok := strings.Cut(qualified, "."); ok && argPkg == pkg {
+		return name
+	}
+	return qualified
+}
+
+// splitTypeList splits a comma-separated type list, ignoring commas nested in [] or {}.
+func splitTypeList(list string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(list[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// topLevelIndex returns the index of the first occurrence of c outside square brackets, or -1.
+func topLevelIndex(s string, c rune) int {
+	depth := 0
+	for i, r := range s {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case r == c && depth == 0:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"testing"
+)
+
+type envelopeTest[T any] struct {
+	Data T            `json:"data"`
+	Meta envelopeMeta `json:"meta"`
+}
+
+type envelopeMeta struct {
+	RequestID string `json:"request_id"`
+}
+
+type pageTest[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+type pairTest[K comparable, V any] struct {
+	Key   K  `json:"key"`
+	Value *V `json:"value,omitempty"`
+}
+
+type legacyEnvelope struct {
+	Data interface{}  `json:"data"`
+	Meta envelopeMeta `json:"meta"`
+}
+
+type genericUser struct {
+	Name string `json:"name"`
+}
+
+func TestGenerateSchema_GenericInstantiation(t *testing.T) {
+	sg := NewSchemaGenerator(newTestIndex(t, "schema_generics_test.go"))
+
+	ref := sg.GenerateSchema("envelopeTest[genericUser]")
+	AssertEqual(t, "#/components/schemas/openapi.envelopeTest_genericUser", ref.Ref)
+
+	schemas := sg.GetSchemas()
+	envelope := schemas["openapi.envelopeTest_genericUser"]
+	AssertEqual(t, "#/components/schemas/openapi.genericUser", envelope.Properties["data"].Ref)
+	AssertEqual(t, "#/components/schemas/openapi.envelopeMeta", envelope.Properties["meta"].Ref)
+	if _, ok := schemas["openapi.genericUser"]; !ok {
+		t.Error("expected type argument schema to be generated")
+	}
+}
+
+func TestGenerateSchema_NestedGenerics(t *testing.T) {
+	sg := NewSchemaGenerator(newTestIndex(t, "schema_generics_test.go"))
+
+	ref := sg.GenerateSchema("envelopeTest[pageTest[genericUser]]")
+	AssertEqual(t, "#/components/schemas/openapi.envelopeTest_pageTest_genericUser", ref.Ref)
+
+	schemas := sg.GetSchemas()
+	AssertEqual(t, "#/components/schemas/openapi.pageTest_genericUser", schemas["openapi.envelopeTest_pageTest_genericUser"].Properties["data"].Ref)
+	items := schemas["openapi.pageTest_genericUser"].Properties["items"]
+	AssertEqual(t, "array", items.Type)
+	AssertEqual(t, "#/components/schemas/openapi.genericUser", items.Items.Ref)
+
+	list := sg.GenerateSchema("envelopeTest[[]genericUser]")
+	AssertEqual(t, "#/components/schemas/openapi.envelopeTest_genericUserList", list.Ref)
+
+	pair := sg.GenerateSchema("pairTest[string, int]")
+	AssertEqual(t, "#/components/schemas/openapi.pairTest_string_int", pair.Ref)
+	pairSchema := sg.GetSchemas()["openapi.pairTest_string_int"]
+	AssertEqual(t, "string", pairSchema.Properties["key"].Type)
+	AssertEqual(t, "integer", pairSchema.Properties["value"].Type)
+	AssertDeepEqual(t, []string{"key"}, pairSchema.Required)
+}
+
+func TestGenerateSchema_FieldOverrides(t *testing.T) {
+	sg := NewSchemaGenerator(newTestIndex(t, "schema_generics_test.go"))
+
+	schema := sg.GenerateSchema("legacyEnvelope{data=[]genericUser, meta=envelopeMeta}")
+	if len(schema.AllOf) != 2 {
+		t.Fatalf("expected allOf composition, got %+v", schema)
+	}
+	AssertEqual(t, "#/components/schemas/openapi.legacyEnvelope", schema.AllOf[0].Ref)
+	data := schema.AllOf[1].Properties["data"]
+	AssertEqual(t, "array", data.Type)
+	AssertEqual(t, "#/components/schemas/openapi.genericUser", data.Items.Ref)
+	AssertEqual(t, "#/components/schemas/openapi.envelopeMeta", schema.AllOf[1].Properties["meta"].Ref)
+}
+
+func TestSplitTypeList(t *testing.T) {
+	AssertDeepEqual(t, []string{"a", "Page[K, V]", "b{x=[]Y,z=Z}"}, splitTypeList("a, Page[K, V], b{x=[]Y,z=Z}"))
+	AssertEqual(t, 0, len(splitTypeList("")))
+}
+
+func TestInstanceName(t *testing.T) {
+	sg := NewSchemaGenerator(newTestIndex(t, "schema_generics_test.go"))
+	AssertEqual(t, "User", sg.instanceName("models.User", "models"))
+	AssertEqual(t, "billing.User", sg.instanceName("billing.User", "models"))
+	AssertEqual(t, "UserList", sg.instanceName("[]*models.User", "models"))
+	AssertEqual(t, "Page_User", sg.instanceName("Page[models.User]", "models"))
+	AssertEqual(t, "genericUser", sg.instanceName("genericUser", "openapi"))
+}
+
+func TestGenerateSchema_GenericArgumentsFromOtherPackages(t *testing.T) {
+	sg := NewSchemaGenerator(newTestIndex(t, "schema_generics_test.go"))
+
+	local := sg.GenerateSchema("envelopeTest[genericUser]")
+	external := sg.GenerateSchema("envelopeTest[billing.genericUser]")
+	AssertEqual(t, "#/components/schemas/openapi.envelopeTest_genericUser", local.Ref)
+	AssertEqual(t, "#/components/schemas/openapi.envelopeTest_billing.genericUser", external.Ref)
+
+	schemas := sg.GetSchemas()
+	AssertEqual(t, "#/components/schemas/openapi.genericUser", schemas["openapi.envelopeTest_genericUser"].Properties["data"].Ref)
+	AssertEqual(t, "#/components/schemas/billing.genericUser", schemas["openapi.envelopeTest_billing.genericUser"].Properties["data"].Ref)
+}
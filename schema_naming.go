@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaNaming maps the qualified name of a type (e.g. "models.User", or "models.Page_User" for
+// a generic instantiation) to the name of its component schema.
+type SchemaNaming func(qualifiedName string) string
+
+// QualifiedSchemaNames keeps the package-qualified names, e.g. "models.User". It is the default.
+func QualifiedSchemaNames(qualifiedName string) string {
+	return qualifiedName
+}
+
+// ShortSchemaNames drops the package qualifier, e.g. "models.User" becomes "User".
+// Types whose short names collide keep their qualified names.
+func ShortSchemaNames(qualifiedName string) string {
+	if _, name, ok := strings.Cut(qualifiedName, "."); ok {
+		return name
+	}
+	return qualifiedName
+}
+
+// componentNames assigns the component schema names of a complete set of qualified names. Names
+// are assigned after every schema is known, so the result does not depend on route order: every
+// member of a group of types whose names collide keeps its qualified name.
+func (sg *SchemaGenerator) componentNames(qualifiedNames []string) map[string]string {
+	names := make(map[string]string, len(qualifiedNames))
+	if sg.naming == nil {
+		for _, qualifiedName := range qualifiedNames {
+			names[qualifiedName] = qualifiedName
+		}
+		return names
+	}
+
+	groups := make(map[string][]string)
+	for _, qualifiedName := range qualifiedNames {
+		name := sg.naming(qualifiedName)
+		if name == "" {
+			name = qualifiedName
+		}
+		groups[name] = append(groups[name], qualifiedName)
+	}
+	var collisions []string
+	for name, members := range groups {
+		if len(members) == 1 {
+			names[members[0]] = name
+			continue
+		}
+		collisions = append(collisions, name)
+		for _, qualifiedName := range members {
+			names[qualifiedName] = qualifiedName
+		}
+	}
+	sort.Strings(collisions)
+	for _, name := range collisions {
+		members := groups[name]
+		sort.Strings(members)
+		sg.log().Warn("[openapi] componentNames: schema names collide, keeping qualified names", "name", name, "types", members)
+	}
+	return names
+}
+
+// schemaRef returns a reference to the component schema of a qualified type name. References use
+// the qualified name until renameSchemaRefs applies the assigned component names.
+func (sg *SchemaGenerator) schemaRef(qualifiedName string) *Schema {
+	return &Schema{Ref: schemaRefPrefix + qualifiedName}
+}
+
+var (
+	schemaType        = reflect.TypeOf(Schema{})
+	schemaPointerType = reflect.TypeOf(&Schema{})
+	discriminatorType = reflect.TypeOf(Discriminator{})
+)
+
+// renameSchemaRefs rewrites the component schema references reachable from spec from qualified
+// names to the names assigned by componentNames. References to names without an assignment are
+// kept. Schemas may still be shared with the generator, so everything reachable is copied before
+// it is renamed and the generator's own schemas are never modified.
+func renameSchemaRefs(spec *Spec, names map[string]string) {
+	c := schemaCopier{names: names, copies: make(map[copyKey]reflect.Value)}
+	c.walk(reflect.ValueOf(spec).Elem())
+}
+
+// cloneSchema returns a deep copy of schema.
+func cloneSchema(schema *Schema) *Schema {
+	c := schemaCopier{copies: make(map[copyKey]reflect.Value)}
+	c.walk(reflect.ValueOf(&schema).Elem())
+	return schema
+}
+
+// copyKey identifies a pointer that has already been copied.
+type copyKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// schemaCopier deep-copies spec values and renames the schema references it finds.
+type schemaCopier struct {
+	names  map[string]string         // qualified name -> component name; nil keeps references
+	copies map[copyKey]reflect.Value // copies of pointers already walked, so sharing and cycles survive
+}
+
+// rename returns ref pointing at the assigned component name.
+func (c *schemaCopier) rename(ref string) string {
+	qualifiedName, ok := strings.CutPrefix(ref, schemaRefPrefix)
+	if !ok {
+		return ref
+	}
+	if name, ok := c.names[qualifiedName]; ok {
+		return schemaRefPrefix + name
+	}
+	return ref
+}
+
+// walk replaces the pointers, maps and slices of the settable value v with copies and renames
+// the references inside them.
+func (c *schemaCopier) walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		key := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if copied, ok := c.copies[key]; ok {
+			v.Set(copied)
+			return
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(v.Elem())
+		c.copies[key] = copied
+		v.Set(copied)
+		c.walk(copied.Elem())
+	case reflect.Interface:
+		// Only schemas are followed; examples and extensions hold arbitrary values
+		if !v.IsNil() && v.Elem().Type() == schemaPointerType {
+			schema := reflect.New(schemaPointerType).Elem()
+			schema.Set(v.Elem())
+			c.walk(schema)
+			v.Set(schema)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				c.walk(v.Field(i))
+			}
+		}
+		switch v.Type() {
+		case schemaType:
+			ref := v.FieldByName("Ref")
+			ref.SetString(c.rename(ref.String()))
+		case discriminatorType:
+			mapping := v.FieldByName("Mapping")
+			for _, key := range mapping.MapKeys() {
+				mapping.SetMapIndex(key, reflect.ValueOf(c.rename(mapping.MapIndex(key).String())))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		for i := 0; i < copied.Len(); i++ {
+			c.walk(copied.Index(i))
+		}
+		v.Set(copied)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.walk(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			// Map values are not addressable: copy each into a variable first
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			c.walk(value)
+			copied.SetMapIndex(iter.Key(), value)
+		}
+		v.Set(copied)
+	}
+}
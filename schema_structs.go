@@ -3,13 +3,12 @@ package openapi
 
 import (
 	"go/ast"
-	"log/slog"
 	"strings"
 )
 
 // convertStructToSchema converts a Go AST struct type into an OpenAPI object schema.
 func (sg *SchemaGenerator) convertStructToSchema(structType *ast.StructType) *Schema {
-	slog.Debug("[openapi] convertStructToSchema: called")
+	sg.log().Debug("[openapi] convertStructToSchema: called")
 	schema := &Schema{
 		Type:       "object",
 		Properties: make(map[string]*Schema),
@@ -30,6 +29,9 @@ func (sg *SchemaGenerator) convertStructToSchema(structType *ast.StructType) *Sc
 		jsonName := fieldName
 		if field.Tag != nil {
 			tag := strings.Trim(field.Tag.Value, "`")
+			if !visibleTo(tagAudiences(tag), sg.audience) {
+				continue // restricted to another audience
+			}
 			if jsonTag := extractJSONTag(tag); jsonTag != "" && jsonTag != "-" {
 				jsonName = jsonTag
 			}
@@ -66,7 +68,7 @@ func (sg *SchemaGenerator) convertStructToSchema(structType *ast.StructType) *Sc
 		}
 
 		// Determine required fields
-		if !isPointerType(field.Type) && !hasOmitEmpty(field.Tag) {
+		if isRequiredField(field) {
 			schema.Required = append(schema.Required, jsonName)
 		}
 	}
@@ -77,7 +79,7 @@ func (sg *SchemaGenerator) convertStructToSchema(structType *ast.StructType) *Sc
 // convertFieldType inspects a Go AST expression and returns its OpenAPI schema representation.
 // It handles identifiers, pointers, arrays, selectors, maps, and empty interfaces.
 func (sg *SchemaGenerator) convertFieldType(expr ast.Expr) *Schema {
-	slog.Debug("[openapi] convertFieldType: called")
+	sg.log().Debug("[openapi] convertFieldType: called")
 
 	switch t := expr.(type) {
 	case *ast.Ident:
@@ -106,6 +108,10 @@ func (sg *SchemaGenerator) convertFieldType(expr ast.Expr) *Schema {
 			return sg.GenerateSchema(qualified)
 		}
 
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		// Generic instantiations (e.g., Page[T] after substitution)
+		return sg.GenerateSchema(genericTypeName(t))
+
 	case *ast.MapType:
 		// Maps as object with additionalProperties
 		return &Schema{Type: "object", AdditionalProperties: sg.convertFieldType(t.Value)}
@@ -115,7 +121,7 @@ func (sg *SchemaGenerator) convertFieldType(expr ast.Expr) *Schema {
 		return &Schema{Type: "object"}
 	}
 
-	slog.Debug("[openapi] convertFieldType: unknown type, defaulting to object")
+	sg.log().Debug("[openapi] convertFieldType: unknown type, defaulting to object")
 	return &Schema{Type: "object"}
 }
 
@@ -125,6 +131,16 @@ func isPointerType(expr ast.Expr) bool {
 	return ok
 }
 
+// isRequiredField reports whether a struct field is required. The rule is shared by schema
+// properties and expanded parameters: a `validate` or `binding` rule of "required" always makes
+// the field required; otherwise it is required unless it is a pointer or tagged omitempty.
+func isRequiredField(field *ast.Field) bool {
+	if field.Tag != nil && hasRequiredValidation(strings.Trim(field.Tag.Value, "`")) {
+		return true
+	}
+	return !isPointerType(field.Type) && !hasOmitEmpty(field.Tag)
+}
+
 // hasOmitEmpty reports whether the struct field tag includes the "omitempty" option.
 func hasOmitEmpty(tag *ast.BasicLit) bool {
 	if tag == nil {
@@ -88,3 +88,27 @@ func Test_extractJSONTag(t *testing.T) {
 		t.Errorf("expected 'foo', got '%s'", got)
 	}
 }
+
+func TestAddExternalKnownType_Scopes(t *testing.T) {
+	ResetGlobals()
+	t.Cleanup(ResetGlobals)
+	idx := &TypeIndex{}
+
+	before := NewSchemaGenerator(idx)
+	AddExternalKnownType("money.Amount", &Schema{Type: "string", Description: "Amount"})
+	after := NewSchemaGenerator(idx)
+
+	if _, ok := before.knownType("money.Amount"); ok {
+		t.Error("expected generators created earlier not to see the registered type")
+	}
+	amount := after.GenerateSchema("money.Amount")
+	AssertEqual(t, "Amount", amount.Description)
+
+	// Returned schemas are copies, and per-generator types stay with their generator
+	amount.Description = "changed"
+	AssertEqual(t, "Amount", after.GenerateSchema("money.Amount").Description)
+	after.AddExternalKnownType("money.Currency", &Schema{Type: "string"})
+	if _, ok := NewSchemaGenerator(idx).knownType("money.Currency"); ok {
+		t.Error("expected per-generator known type not to leak into new generators")
+	}
+}
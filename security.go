@@ -0,0 +1,64 @@
+// Package openapi provides security scheme configuration and pruning.
+package openapi
+
+import (
+	"log/slog"
+)
+
+// defaultSecuritySchemes are published when Config.SecuritySchemes is not set.
+func defaultSecuritySchemes() map[string]SecurityScheme {
+	return map[string]SecurityScheme{
+		"BearerAuth": {
+			Type:         "http",
+			Scheme:       "bearer",
+			BearerFormat: "JWT",
+			Description:  "JWT token authentication",
+		},
+	}
+}
+
+// addSecuritySchemes registers the configured security schemes and the default requirements.
+func addSecuritySchemes(spec *Spec, cfg Config) {
+	schemes := cfg.SecuritySchemes
+	if schemes == nil {
+		schemes = defaultSecuritySchemes()
+	}
+	for name, scheme := range schemes {
+		spec.Components.SecuritySchemes[name] = scheme
+	}
+	spec.Security = cfg.Security
+}
+
+// finalizeSecuritySchemes warns about requirements naming undeclared schemes and, when pruning is
+// enabled, removes schemes that neither the default requirements nor any operation reference.
+func finalizeSecuritySchemes(spec *Spec, prune bool, logger *slog.Logger) {
+	used := make(map[string]bool)
+	collect := func(requirements []SecurityRequirement) {
+		for _, requirement := range requirements {
+			for name := range requirement {
+				used[name] = true
+			}
+		}
+	}
+	collect(spec.Security)
+	for _, item := range spec.Paths {
+		for _, operation := range item.Operations() {
+			collect(operation.Security)
+		}
+	}
+
+	for name := range used {
+		if _, ok := spec.Components.SecuritySchemes[name]; !ok {
+			logger.Warn("[openapi] finalizeSecuritySchemes: security requirement references undeclared scheme", "scheme", name)
+		}
+	}
+	if !prune {
+		return
+	}
+	for name := range spec.Components.SecuritySchemes {
+		if !used[name] {
+			logger.Debug("[openapi] finalizeSecuritySchemes: pruning unused scheme", "scheme", name)
+			delete(spec.Components.SecuritySchemes, name)
+		}
+	}
+}
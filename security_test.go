@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// readTestReports requires an OAuth2 scope.
+// @Summary Read reports
+// @Security OAuth2[reports:read]
+func readTestReports(w http.ResponseWriter, r *http.Request) {}
+
+// healthTestCheck is public.
+// @Summary Health
+// @Security none
+func healthTestCheck(w http.ResponseWriter, r *http.Request) {}
+
+func testSecuritySchemes() map[string]SecurityScheme {
+	return map[string]SecurityScheme{
+		"ApiKeyCookie": {Type: "apiKey", In: "cookie", Name: "session"},
+		"OAuth2": {
+			Type: "oauth2",
+			Flows: &OAuthFlows{
+				AuthorizationCode: &OAuthFlow{
+					AuthorizationURL: "https://auth.example.com/authorize",
+					TokenURL:         "https://auth.example.com/token",
+					Scopes:           map[string]string{"reports:read": "Read reports"},
+				},
+				ClientCredentials: &OAuthFlow{
+					TokenURL: "https://auth.example.com/token",
+					Scopes:   map[string]string{},
+				},
+			},
+		},
+		"OIDC":   {Type: "openIdConnect", OpenIDConnectURL: "https://auth.example.com/.well-known/openid-configuration"},
+		"Mutual": {Type: "mutualTLS"},
+	}
+}
+
+func TestGenerateSpec_DefaultSecurityScheme(t *testing.T) {
+	spec := NewTestGenerator().GenerateSpec(chi.NewRouter(), Config{Title: "Test", Version: "1.0.0"})
+	AssertEqual(t, "bearer", spec.Components.SecuritySchemes["BearerAuth"].Scheme)
+	AssertEqual(t, 0, len(spec.Security))
+}
+
+func TestGenerateSpec_ConfiguredSecuritySchemes(t *testing.T) {
+	g := NewGeneratorWithCache(newTestIndex(t, "security_test.go"))
+	r := chi.NewRouter()
+	r.Get("/reports", readTestReports)
+	r.Get("/health", healthTestCheck)
+
+	cfg := Config{
+		Title:           "Test",
+		Version:         "1.0.0",
+		SecuritySchemes: testSecuritySchemes(),
+		Security:        []SecurityRequirement{{"ApiKeyCookie": {}}},
+	}
+	spec := g.GenerateSpec(r, cfg)
+
+	AssertEqual(t, 4, len(spec.Components.SecuritySchemes))
+	if _, ok := spec.Components.SecuritySchemes["BearerAuth"]; ok {
+		t.Error("expected configured schemes to replace the default BearerAuth scheme")
+	}
+	AssertDeepEqual(t, []SecurityRequirement{{"ApiKeyCookie": {}}}, spec.Security)
+
+	data, err := json.Marshal(spec.Components.SecuritySchemes)
+	AssertNoError(t, err)
+	var schemes map[string]map[string]interface{}
+	AssertNoError(t, json.Unmarshal(data, &schemes))
+	AssertEqual(t, "cookie", schemes["ApiKeyCookie"]["in"])
+	AssertEqual(t, "https://auth.example.com/.well-known/openid-configuration", schemes["OIDC"]["openIdConnectUrl"])
+	flows := schemes["OAuth2"]["flows"].(map[string]interface{})
+	clientCredentials := flows["clientCredentials"].(map[string]interface{})
+	AssertDeepEqual(t, map[string]interface{}{}, clientCredentials["scopes"])
+
+	// Pruning keeps only schemes referenced by the default or operation requirements
+	cfg.PruneSecuritySchemes = true
+	spec = g.GenerateSpec(r, cfg)
+	AssertEqual(t, 2, len(spec.Components.SecuritySchemes))
+	for _, name := range []string{"ApiKeyCookie", "OAuth2"} {
+		if _, ok := spec.Components.SecuritySchemes[name]; !ok {
+			t.Errorf("expected %s to be retained", name)
+		}
+	}
+}
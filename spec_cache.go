@@ -0,0 +1,198 @@
+package openapi
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SpecCache generates and caches the OpenAPI specification of one router and Config.
+// Each cache is independent, so a process can serve several specs (e.g. /v1 and /v2 docs)
+// and invalidate them separately. Concurrent requests that miss the cache share a single
+// generation instead of each regenerating the spec.
+type SpecCache struct {
+	router    chi.Router
+	cfg       Config
+	generator func() *Generator // returns the generator shared by every regeneration
+
+	mutex    sync.Mutex
+	spec     *Spec
+	err      error  // error of the generation that produced spec
+	version  uint64 // incremented by Invalidate so in-flight generations are not cached
+	inflight *specGeneration
+
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+// specGeneration is a spec generation in progress; done is closed once spec and err are set.
+type specGeneration struct {
+	done chan struct{}
+	spec Spec
+	err  error
+}
+
+// NewSpecCache creates a cache for the router's specification. Nothing is generated until the
+// spec is first requested or WarmUp is called. The first generation creates a Generator
+// configured by opts; later regenerations reuse it and its TypeIndex, so Refresh and Invalidate
+// do not walk the module again.
+func NewSpecCache(router chi.Router, cfg Config, opts ...Option) *SpecCache {
+	var (
+		once      sync.Once
+		generator *Generator
+	)
+	return &SpecCache{
+		router: router,
+		cfg:    cfg,
+		generator: func() *Generator {
+			once.Do(func() { generator = NewGenerator(opts...) })
+			return generator
+		},
+		ready: make(chan struct{}),
+	}
+}
+
+// Spec returns the cached specification, generating it on a cache miss. Generation errors are
+// logged; use Load to receive them.
+func (c *SpecCache) Spec() Spec {
+	spec, _ := c.load(false)
+	return spec
+}
+
+// Load is like Spec but also returns the error of the generation that produced the spec, such as
+// a *StrictModeError when the cache was created with WithStrict. The spec and its error are
+// cached together until the cache is invalidated or refreshed.
+func (c *SpecCache) Load() (Spec, error) {
+	return c.load(false)
+}
+
+// Refresh regenerates the specification and caches the result.
+// If a generation is already in progress, Refresh waits for it instead of starting another.
+func (c *SpecCache) Refresh() Spec {
+	spec, _ := c.load(true)
+	return spec
+}
+
+// Invalidate drops the cached specification. The next request regenerates it, and a generation
+// already in progress is not cached.
+func (c *SpecCache) Invalidate() {
+	c.mutex.Lock()
+	c.spec, c.err = nil, nil
+	c.version++
+	c.mutex.Unlock()
+	slog.Debug("[openapi] SpecCache.Invalidate: cache invalidated", "title", c.cfg.Title)
+}
+
+// WarmUp generates the specification in the background, e.g. at startup, so the first request
+// is served from the cache. It returns the Ready channel.
+func (c *SpecCache) WarmUp() <-chan struct{} {
+	go c.load(false)
+	return c.Ready()
+}
+
+// Ready returns a channel that is closed once a specification has been generated.
+// It stays closed after Invalidate, because a stale spec can always be regenerated.
+func (c *SpecCache) Ready() <-chan struct{} {
+	return c.ready
+}
+
+// IsReady reports whether a specification has been generated.
+func (c *SpecCache) IsReady() bool {
+	select {
+	case <-c.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// Handler returns an HTTP handler that serves the cached specification as JSON.
+// Passing refresh=true as a query parameter regenerates it. If generation failed, for example
+// in strict mode, it responds with 500 Internal Server Error instead.
+func (c *SpecCache) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		spec, err := c.load(r.URL.Query().Get("refresh") == "true")
+		if err != nil {
+			http.Error(w, "Failed to generate OpenAPI spec", http.StatusInternalServerError)
+			return
+		}
+		writeSpec(w, spec)
+	}
+}
+
+// InvalidateHandler returns an HTTP handler that invalidates this cache.
+func (c *SpecCache) InvalidateHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		c.Invalidate()
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// ReadyHandler returns an HTTP handler for readiness probes: 200 once the specification has been
+// generated, 503 until then.
+func (c *SpecCache) ReadyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		if !c.IsReady() {
+			http.Error(w, "OpenAPI spec not generated yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// WriteFile writes the cached specification to filePath as indented JSON. It writes nothing and
+// returns the error if generation failed.
+func (c *SpecCache) WriteFile(filePath string) error {
+	spec, err := c.load(false)
+	if err != nil {
+		return err
+	}
+	return writeSpecFile(spec, filePath)
+}
+
+// load returns the cached spec and its error unless refresh is set or the cache is empty.
+// Concurrent callers that need a new spec join the generation in progress.
+func (c *SpecCache) load(refresh bool) (Spec, error) {
+	c.mutex.Lock()
+	if c.spec != nil && !refresh {
+		spec, err := *c.spec, c.err
+		c.mutex.Unlock()
+		return spec, err
+	}
+	if call := c.inflight; call != nil {
+		c.mutex.Unlock()
+		slog.Debug("[openapi] SpecCache.load: waiting for generation in progress", "title", c.cfg.Title)
+		<-call.done
+		return call.spec, call.err
+	}
+	call := &specGeneration{done: make(chan struct{})}
+	c.inflight = call
+	version := c.version
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		c.inflight = nil
+		c.mutex.Unlock()
+		close(call.done)
+	}()
+
+	slog.Debug("[openapi] SpecCache.load: generating spec", "title", c.cfg.Title, "refresh", refresh)
+	generator := c.generator()
+	call.spec, call.err = generator.GenerateSpecContext(context.Background(), c.router, c.cfg)
+	if call.err != nil {
+		generator.log().Error("[openapi] SpecCache.load: generation failed", "title", c.cfg.Title, "error", call.err)
+	}
+
+	c.mutex.Lock()
+	if c.version == version {
+		spec := call.spec
+		c.spec, c.err = &spec, call.err
+	}
+	c.mutex.Unlock()
+	c.readyOnce.Do(func() { close(c.ready) })
+	return call.spec, call.err
+}
@@ -0,0 +1,239 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// countingSpecCache returns a cache whose generations are counted and, when release is non-nil,
+// blocked until release is closed.
+func countingSpecCache(t *testing.T, cfg Config, calls *atomic.Int32, release chan struct{}) *SpecCache {
+	idx := newTestIndex(t, "spec_cache_test.go")
+	cache := NewSpecCache(chi.NewRouter(), cfg)
+	cache.generator = func() *Generator {
+		calls.Add(1)
+		if release != nil {
+			<-release
+		}
+		return NewGeneratorWithCache(idx)
+	}
+	return cache
+}
+
+func TestSpecCache_IndependentInstances(t *testing.T) {
+	var v1Calls, v2Calls atomic.Int32
+	v1 := countingSpecCache(t, Config{Title: "API", Version: "1.0.0"}, &v1Calls, nil)
+	v2 := countingSpecCache(t, Config{Title: "API", Version: "2.0.0"}, &v2Calls, nil)
+
+	AssertEqual(t, "1.0.0", v1.Spec().Info.Version)
+	AssertEqual(t, "2.0.0", v2.Spec().Info.Version)
+	AssertEqual(t, "1.0.0", v1.Spec().Info.Version)
+	AssertEqual(t, int32(1), v1Calls.Load())
+
+	v1.Invalidate()
+	v1.Spec()
+	v2.Spec()
+	AssertEqual(t, int32(2), v1Calls.Load())
+	AssertEqual(t, int32(1), v2Calls.Load())
+
+	v2.Refresh()
+	AssertEqual(t, int32(2), v2Calls.Load())
+}
+
+func TestSpecCache_ConcurrentMissesShareGeneration(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	cache := countingSpecCache(t, Config{Title: "API", Version: "1.0.0"}, &calls, release)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if spec := cache.Spec(); spec.Info.Title != "API" {
+				t.Errorf("expected generated spec, got %+v", spec.Info)
+			}
+		}()
+	}
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	AssertEqual(t, int32(1), calls.Load())
+}
+
+func TestSpecCache_InvalidateDuringGeneration(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	cache := countingSpecCache(t, Config{Title: "API", Version: "1.0.0"}, &calls, release)
+
+	done := make(chan struct{})
+	go func() {
+		cache.Spec()
+		close(done)
+	}()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cache.Invalidate()
+	close(release)
+	<-done
+
+	cache.Spec()
+	AssertEqual(t, int32(2), calls.Load())
+}
+
+func TestSpecCache_ReusesGeneratorAndIndex(t *testing.T) {
+	cache := NewSpecCache(chi.NewRouter(), Config{Title: "API", Version: "1.0.0"})
+	cache.Spec()
+	generator := cache.generator()
+	idx := generator.schemaGen.typeIndex
+	if idx == nil {
+		t.Fatal("expected the first generation to build the TypeIndex")
+	}
+
+	cache.Invalidate()
+	cache.Spec()
+	cache.Refresh()
+	if cache.generator() != generator || cache.generator().schemaGen.typeIndex != idx {
+		t.Error("expected regenerations to reuse the generator and its TypeIndex")
+	}
+}
+
+func TestSpecCache_WarmUpAndReadiness(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	cache := countingSpecCache(t, Config{Title: "API", Version: "1.0.0"}, &calls, release)
+
+	rec := httptest.NewRecorder()
+	cache.ReadyHandler()(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	AssertEqual(t, http.StatusServiceUnavailable, rec.Code)
+
+	ready := cache.WarmUp()
+	AssertEqual(t, false, cache.IsReady())
+	close(release)
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("warm-up did not complete")
+	}
+
+	rec = httptest.NewRecorder()
+	cache.ReadyHandler()(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	AssertEqual(t, http.StatusOK, rec.Code)
+
+	cache.Spec()
+	AssertEqual(t, int32(1), calls.Load())
+}
+
+func TestSpecCache_Handlers(t *testing.T) {
+	var calls atomic.Int32
+	cache := countingSpecCache(t, Config{Title: "API", Version: "1.0.0"}, &calls, nil)
+
+	for _, target := range []string{"/openapi", "/openapi", "/openapi?refresh=true"} {
+		rec := httptest.NewRecorder()
+		cache.Handler()(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		AssertEqual(t, http.StatusOK, rec.Code)
+		AssertEqual(t, "application/json", rec.Header().Get("Content-Type"))
+	}
+	AssertEqual(t, int32(2), calls.Load())
+
+	rec := httptest.NewRecorder()
+	cache.InvalidateHandler()(rec, httptest.NewRequest(http.MethodPost, "/invalidate", nil))
+	AssertEqual(t, http.StatusOK, rec.Code)
+	cache.Spec()
+	AssertEqual(t, int32(3), calls.Load())
+}
+
+func TestInvalidateCache_HandlerCaches(t *testing.T) {
+	var calls atomic.Int32
+	cache := registerHandlerCache(countingSpecCache(t, Config{Title: "API", Version: "1.0.0"}, &calls, nil))
+	cache.Spec()
+
+	InvalidateCache(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/invalidate", nil))
+	cache.Spec()
+	AssertEqual(t, int32(2), calls.Load())
+}
+
+func TestInvalidateCache_ReleasesDiscardedCaches(t *testing.T) {
+	runtime.GC()
+	InvalidateCache(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/invalidate", nil))
+	handlerCaches.Lock()
+	before := len(handlerCaches.caches)
+	handlerCaches.Unlock()
+
+	for i := 0; i < 10; i++ {
+		CachedHandler(chi.NewRouter(), Config{Title: "API", Version: "1.0.0"})
+	}
+	runtime.GC()
+	InvalidateCache(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/invalidate", nil))
+
+	handlerCaches.Lock()
+	defer handlerCaches.Unlock()
+	AssertEqual(t, before, len(handlerCaches.caches))
+}
+
+func TestSpecCache_StrictModeErrors(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/widgets", func(w http.ResponseWriter, r *http.Request) {})
+	cfg := Config{Title: "API", Version: "1.0.0", Security: []SecurityRequirement{{"MissingAuth": {}}}}
+	cache := NewSpecCache(r, cfg,
+		WithTypeIndex(newTestIndex(t, "spec_cache_test.go")),
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithStrict(),
+	)
+
+	spec, err := cache.Load()
+	var strictErr *StrictModeError
+	if !errors.As(err, &strictErr) {
+		t.Fatalf("expected StrictModeError, got %v", err)
+	}
+	AssertEqual(t, 1, len(spec.Paths))
+	// The error is cached with the spec
+	if _, err := cache.Load(); !errors.As(err, &strictErr) {
+		t.Errorf("expected cached StrictModeError, got %v", err)
+	}
+
+	AssertEqual(t, http.StatusInternalServerError, Request(cache.Handler(), http.MethodGet, "/openapi", nil).Code)
+	if err := cache.WriteFile(filepath.Join(t.TempDir(), "openapi.json")); !errors.As(err, &strictErr) {
+		t.Errorf("expected WriteFile to return the StrictModeError, got %v", err)
+	}
+}
+
+// TestSpecCache_RefreshWhileServing encodes a served spec while the cache regenerates it with
+// renamed schemas. Run with -race.
+func TestSpecCache_RefreshWhileServing(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/users", getTestNamingUser)
+	cache := NewSpecCache(r, Config{Title: "API", Version: "1.0.0"},
+		WithTypeIndex(newTestIndex(t, "options_test.go")),
+		WithSchemaNaming(ShortSchemaNames),
+	)
+	spec := cache.Spec()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			cache.Refresh()
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		_, err := json.Marshal(spec)
+		AssertNoError(t, err)
+	}
+	<-done
+}
@@ -11,10 +11,11 @@ import (
 	"testing"
 )
 
-// ResetGlobals resets any global state for testing.
+// ResetGlobals resets the package-level registries (middleware documentation and
+// types added with AddExternalKnownType) for testing.
 func ResetGlobals() {
-	resetTypeIndexForTesting()
-	ensureTypeIndex()
+	resetMiddlewareDocsForTesting()
+	resetKnownTypesForTesting()
 }
 
 // NewTestSchemaGenerator resets globals and returns a SchemaGenerator.
@@ -23,10 +24,11 @@ func NewTestSchemaGenerator() *SchemaGenerator {
 	return NewSchemaGenerator()
 }
 
-// NewTestGenerator resets globals and returns a Generator.
+// NewTestGenerator resets globals and returns a Generator with the project already indexed,
+// so tests can call its builders without generating a spec first.
 func NewTestGenerator() *Generator {
 	ResetGlobals()
-	return NewGenerator()
+	return NewGenerator(WithTypeIndex(BuildTypeIndex()))
 }
 
 // AssertEqual fails the test if expected != actual.